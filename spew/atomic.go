@@ -0,0 +1,61 @@
+// Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package spew
+
+import "reflect"
+
+// atomicPkgPath is the import path of the sync/atomic package.  Its types are
+// detected by their package path rather than by name so the ones introduced in
+// later versions of Go, including the generic atomic.Pointer, are handled
+// without build constraints.
+const atomicPkgPath = "sync/atomic"
+
+// atomicLoad returns the current value stored in the passed value when it is
+// one of the types provided by the sync/atomic package, such as atomic.Int64,
+// atomic.Bool, atomic.Pointer, and atomic.Value.  The value is obtained via the
+// Load method since, unlike reading the internals, it is safe for concurrent
+// use.  As with handleMethods, unsafe is used, when it's available, to access
+// values which are stored in unexported struct fields.  The boolean return is
+// false when the value is not of a sync/atomic type or can't be accessed.
+func atomicLoad(v reflect.Value) (reflect.Value, bool) {
+	vt := v.Type()
+	if vt.Kind() != reflect.Struct || vt.PkgPath() != atomicPkgPath {
+		return reflect.Value{}, false
+	}
+	load, ok := reflect.PtrTo(vt).MethodByName("Load")
+	if !ok || load.Type.NumIn() != 1 || load.Type.NumOut() != 1 {
+		return reflect.Value{}, false
+	}
+
+	if !v.CanInterface() {
+		if UnsafeDisabled {
+			return reflect.Value{}, false
+		}
+
+		v = unsafeReflectValue(v)
+		if !v.CanInterface() {
+			return reflect.Value{}, false
+		}
+	}
+
+	// Load requires a pointer receiver, so make an addressable copy when the
+	// value itself isn't addressable.
+	if !v.CanAddr() {
+		pv := reflect.New(vt)
+		pv.Elem().Set(v)
+		v = pv.Elem()
+	}
+	return v.Addr().Method(load.Index).Call(nil)[0], true
+}
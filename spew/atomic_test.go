@@ -0,0 +1,93 @@
+// Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// NOTE: Due to the following build constraints, this file will only be compiled
+// with Go 1.19 and later since that is when the typed atomic values, such as
+// atomic.Int64 and atomic.Pointer, were introduced.
+// +build go1.19
+
+package spew_test
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// atomicHolder is used to test that sync/atomic values stored in unexported
+// struct fields are displayed by their current value.
+type atomicHolder struct {
+	count atomic.Int64
+	ready atomic.Bool
+	ptr   atomic.Pointer[int]
+	val   atomic.Value
+}
+
+// TestAtomic ensures the values of sync/atomic types are dumped by loading
+// them unless DisableAtomicExpansion is set.
+func TestAtomic(t *testing.T) {
+	cs := &spew.ConfigState{Indent: " "}
+
+	var i64 atomic.Int64
+	i64.Store(42)
+	got := cs.Sdump(&i64)
+	want := fmt.Sprintf("(*atomic.Int64)(%p)(42)\n", &i64)
+	if got != want {
+		t.Errorf("atomic.Int64 pointer\n got: %s want: %s", got, want)
+	}
+
+	var u32 atomic.Uint32
+	u32.Store(7)
+	if got, want := cs.Sdump(&u32), fmt.Sprintf("(*atomic.Uint32)(%p)(7)\n",
+		&u32); got != want {
+		t.Errorf("atomic.Uint32 pointer\n got: %s want: %s", got, want)
+	}
+
+	// Values stored in unexported fields can only be loaded when unsafe is
+	// available.
+	if !spew.UnsafeDisabled {
+		n := 5
+		h := &atomicHolder{}
+		h.count.Store(3)
+		h.ready.Store(true)
+		h.ptr.Store(&n)
+		h.val.Store("hi")
+		got = cs.Sdump(h)
+		want = fmt.Sprintf("(*spew_test.atomicHolder)(%p)({\n"+
+			" count: (atomic.Int64) 3,\n"+
+			" ready: (atomic.Bool) true,\n"+
+			" ptr: (atomic.Pointer[int]) (*int)(%p)(5),\n"+
+			" val: (atomic.Value) (string) (len=2) \"hi\"\n"+
+			"})\n", h, &n)
+		if got != want {
+			t.Errorf("atomic fields\n got: %s want: %s", got, want)
+		}
+	}
+
+	var empty atomic.Value
+	got = cs.Sdump(&empty)
+	want = fmt.Sprintf("(*atomic.Value)(%p)((interface {}) <nil>)\n", &empty)
+	if got != want {
+		t.Errorf("empty atomic.Value\n got: %s want: %s", got, want)
+	}
+
+	cs.DisableAtomicExpansion = true
+	got = cs.Sdump(&i64)
+	if !strings.Contains(got, "v: (int64) 42") {
+		t.Errorf("atomic.Int64 with DisableAtomicExpansion\n got: %s", got)
+	}
+}
@@ -46,6 +46,10 @@ var (
 	// flagAddr indicates whether the address of the reflect.Value's
 	// value may be taken.
 	flagAddr flag
+
+	// flagIndir indicates whether the value field of a reflect.Value is
+	// a pointer to the data rather than the data itself.
+	flagIndir flag
 )
 
 // flagKindMask holds the bits that make up the kind
@@ -57,15 +61,17 @@ const flagKindMask = flag(0x1f)
 // bit layouts for the flags type. This table
 // records the known combinations.
 var okFlags = []struct {
-	ro, addr flag
+	ro, addr, indir flag
 }{{
 	// From Go 1.4 to 1.5
-	ro:   1 << 5,
-	addr: 1 << 7,
+	ro:    1 << 5,
+	addr:  1 << 7,
+	indir: 1 << 6,
 }, {
 	// Up to Go tip.
-	ro:   1<<5 | 1<<6,
-	addr: 1 << 8,
+	ro:    1<<5 | 1<<6,
+	addr:  1 << 8,
+	indir: 1 << 7,
 }}
 
 var flagValOffset = func() uintptr {
@@ -96,7 +102,14 @@ func unsafeReflectValue(v reflect.Value) reflect.Value {
 	}
 	flagFieldPtr := flagField(&v)
 	*flagFieldPtr &^= flagRO
-	*flagFieldPtr |= flagAddr
+
+	// Only values which are stored indirectly may be marked addressable.
+	// The value field of a direct value, such as a pointer or a struct
+	// consisting of a single pointer, is the data itself, so taking its
+	// address would produce a pointer to the wrong memory.
+	if *flagFieldPtr&flagIndir != 0 {
+		*flagFieldPtr |= flagAddr
+	}
 	return v
 }
 
@@ -135,9 +148,15 @@ func init() {
 	flagPtr := *flagField(&vPtrA)
 	flagAddr = flagNoPtr ^ flagPtr
 
+	// Infer flagIndir from the difference between a value which is stored
+	// indirectly and a pointer which is stored directly, ignoring the kind.
+	vIndir := reflect.ValueOf(t)
+	vDirect := reflect.ValueOf(&t)
+	flagIndir = (*flagField(&vIndir) ^ *flagField(&vDirect)) &^ flagKindMask
+
 	// Check that the inferred flags tally with one of the known versions.
 	for _, f := range okFlags {
-		if flagRO == f.ro && flagAddr == f.addr {
+		if flagRO == f.ro && flagAddr == f.addr && flagIndir == f.indir {
 			return
 		}
 	}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// Some constants in the form of bytes used when producing a byte dump.
+var (
+	byteDumpGutterBytes = []byte(" |")
+	byteDumpEndBytes    = []byte("|\n")
+	byteSummaryBytes    = []byte(" bytes: ")
+)
+
+// byteSummaryEdge is the number of bytes shown from each end of the data by
+// writeByteSummary.
+const byteSummaryEdge = 4
+
+// writeByteDump outputs the passed data to Writer w in the same layout as the
+// hexdump -C command, which is also used by hex.Dump, with the passed number
+// of bytes on each line.  Each line consists of the offset of its first byte,
+// the bytes in hex with an extra space after every group of eight, and the
+// printable ASCII characters between vertical bars.  The hex columns of the
+// final line are padded so the ASCII gutter stays aligned.  Empty data results
+// in no output.
+func writeByteDump(w io.Writer, data []byte, perLine int) {
+	line := make([]byte, 0, 11+perLine*4+perLine/8+3)
+	for offset := 0; offset < len(data); offset += perLine {
+		end := offset + perLine
+		if end > len(data) {
+			end = len(data)
+		}
+
+		line = line[:0]
+		for shift := uint(28); ; shift -= 4 {
+			line = append(line, hexDigits[(offset>>shift)&0x0f])
+			if shift == 0 {
+				break
+			}
+		}
+		line = append(line, ' ', ' ')
+		for i := 0; i < perLine; i++ {
+			if offset+i < end {
+				b := data[offset+i]
+				line = append(line, hexDigits[b>>4], hexDigits[b&0x0f], ' ')
+			} else {
+				line = append(line, ' ', ' ', ' ')
+			}
+			if (i+1)%8 == 0 && i+1 < perLine {
+				line = append(line, ' ')
+			}
+		}
+		line = append(line, byteDumpGutterBytes...)
+		for _, b := range data[offset:end] {
+			if b < 32 || b > 126 {
+				b = '.'
+			}
+			line = append(line, b)
+		}
+		line = append(line, byteDumpEndBytes...)
+		w.Write(line)
+	}
+}
+
+// writeByteSummary outputs a terse summary of the passed data to Writer w which
+// consists of its length along with its first and last few bytes in hex
+// separated by an ellipsis, such as <32 bytes: 00112233...ccddeeff>.  Data
+// which is too short to have bytes omitted is output in hex in its entirety.
+func writeByteSummary(w io.Writer, data []byte) {
+	buf := make([]byte, 0, 32)
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(len(data)), 10)
+	buf = append(buf, byteSummaryBytes...)
+	appendHex := func(data []byte) {
+		for _, b := range data {
+			buf = append(buf, hexDigits[b>>4], hexDigits[b&0x0f])
+		}
+	}
+	if len(data) <= byteSummaryEdge*2 {
+		appendHex(data)
+	} else {
+		appendHex(data[:byteSummaryEdge])
+		buf = append(buf, ellipsisBytes...)
+		appendHex(data[len(data)-byteSummaryEdge:])
+	}
+	buf = append(buf, '>')
+	w.Write(buf)
+}
+
+// fbytedump is a helper function to consolidate the logic from the various
+// public byte dump methods which take varying config states.
+func fbytedump(cs *ConfigState, w io.Writer, data []byte) (n int, err error) {
+	ew := &errWriter{w: w}
+	writeByteDump(ew, data, cs.bytesPerLine())
+	return ew.n, ew.err
+}
+
+// Fbytedump formats and displays the passed data to io.Writer w in the classic
+// offset, hex, and ASCII layout of the hexdump -C command with the number of
+// bytes on each line determined by the BytesPerLine option.  It returns the
+// number of bytes written and the first write error encountered, after which
+// nothing more is written.
+func Fbytedump(w io.Writer, data []byte) (n int, err error) {
+	return fbytedump(&Config, w, data)
+}
+
+// Bytedump returns a string with the passed data formatted exactly the same as
+// Fbytedump.  Empty data results in an empty string.  For example:
+//
+//	00000000  68 65 6c 6c 6f 0a                                 |hello.|
+func Bytedump(data []byte) string {
+	var buf bytes.Buffer
+	fbytedump(&Config, &buf, data)
+	return buf.String()
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestBytedump ensures byte dumps match hex.Dump by default and honor the
+// BytesPerLine option.
+func TestBytedump(t *testing.T) {
+	data := make([]byte, 40)
+	for i := range data {
+		data[i] = byte(i*7 + 20)
+	}
+
+	// The default layout is the same as hex.Dump for every length.
+	for n := 0; n <= len(data); n++ {
+		got := spew.Bytedump(data[:n])
+		if want := hex.Dump(data[:n]); got != want {
+			t.Errorf("Bytedump of %d bytes\n got: %q\nwant: %q", n, got, want)
+		}
+	}
+
+	cs := &spew.ConfigState{BytesPerLine: 4}
+	got := cs.Bytedump([]byte("hello"))
+	want := "00000000  68 65 6c 6c  |hell|\n" +
+		"00000004  6f           |o|\n"
+	if got != want {
+		t.Errorf("Bytedump BytesPerLine 4\n got: %q\nwant: %q", got, want)
+	}
+
+	cs = &spew.ConfigState{BytesPerLine: 10}
+	got = cs.Bytedump([]byte("0123456789ab\x00"))
+	want = "00000000  30 31 32 33 34 35 36 37  38 39  |0123456789|\n" +
+		"0000000a  61 62 00                        |ab.|\n"
+	if got != want {
+		t.Errorf("Bytedump BytesPerLine 10\n got: %q\nwant: %q", got, want)
+	}
+
+	// Hexdumps within Dump output honor the option as well.
+	cs = &spew.ConfigState{Indent: " ", BytesPerLine: 4}
+	got = cs.Sdump([]byte("hello"))
+	want = "([]uint8) (len=5 cap=5) {\n" +
+		" 00000000  68 65 6c 6c  |hell|\n" +
+		" 00000004  6f           |o|\n" +
+		"}\n"
+	if got != want {
+		t.Errorf("Dump BytesPerLine 4\n got: %q\nwant: %q", got, want)
+	}
+}
+
+// TestFbytedumpResult ensures Fbytedump returns the number of bytes written
+// and the first write error encountered.
+func TestFbytedumpResult(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := spew.Fbytedump(&buf, []byte("hi"))
+	if err != nil || n != buf.Len() {
+		t.Errorf("Fbytedump got n=%d err=%v, want n=%d err=nil", n, err,
+			buf.Len())
+	}
+
+	w := &limitedWriter{max: 10}
+	n, err = (&spew.ConfigState{}).Fbytedump(w, []byte("hi"))
+	if err != errLimitReached || n != 10 {
+		t.Errorf("Fbytedump got n=%d err=%v, want n=10 err=%v", n, err,
+			errLimitReached)
+	}
+}
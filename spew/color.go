@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+// colorResetBytes is the ANSI escape sequence which resets all colors and
+// attributes.  It is written after each colored token.
+var colorResetBytes = []byte("\x1b[0m")
+
+// Colors houses the ANSI escape sequences used to color each category of token
+// in the output of Dump and its variants when the EnableColors option is set.
+// An empty string for a category leaves those tokens uncolored.
+type Colors struct {
+	// Type is used for type information such as (int) or (*main.Foo).
+	Type string
+
+	// FieldName is used for the names of struct fields.
+	FieldName string
+
+	// String is used for quoted string literals.
+	String string
+
+	// Number is used for integer, floating point, and complex values.
+	Number string
+
+	// Pointer is used for pointer addresses as well as uintptr, unsafe
+	// pointer, channel, and func values.
+	Pointer string
+
+	// Nil is used for nil values.
+	Nil string
+}
+
+// DefaultColors is the color scheme used when the EnableColors option is set
+// and no custom Colors are specified.
+var DefaultColors = Colors{
+	Type:      "\x1b[32m", // green
+	FieldName: "\x1b[33m", // yellow
+	String:    "\x1b[31m", // red
+	Number:    "\x1b[34m", // blue
+	Pointer:   "\x1b[35m", // magenta
+	Nil:       "\x1b[36m", // cyan
+}
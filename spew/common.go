@@ -18,11 +18,21 @@ package spew
 
 import (
 	"bytes"
+	"encoding"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
+	"math/cmplx"
 	"reflect"
+	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Some constants in the form of bytes to avoid string overhead.  This mirrors
@@ -34,11 +44,14 @@ var (
 	trueBytes             = []byte("true")
 	falseBytes            = []byte("false")
 	interfaceBytes        = []byte("(interface {})")
+	interfaceTypeBytes    = []byte("interface {}")
 	commaNewlineBytes     = []byte(",\n")
+	commaSpaceBytes       = []byte(", ")
 	newlineBytes          = []byte("\n")
 	openBraceBytes        = []byte("{")
 	openBraceNewlineBytes = []byte("{\n")
 	closeBraceBytes       = []byte("}")
+	emptyBracesBytes      = []byte("{}")
 	asteriskBytes         = []byte("*")
 	colonBytes            = []byte(":")
 	colonSpaceBytes       = []byte(": ")
@@ -46,11 +59,13 @@ var (
 	closeParenBytes       = []byte(")")
 	spaceBytes            = []byte(" ")
 	pointerChainBytes     = []byte("->")
+	ellipsisBytes         = []byte("...")
 	nilAngleBytes         = []byte("<nil>")
-	maxNewlineBytes       = []byte("<max depth reached>\n")
+	maxDepthBytes         = []byte("<max depth reached>")
 	maxShortBytes         = []byte("<max>")
 	circularBytes         = []byte("<already shown>")
 	circularShortBytes    = []byte("<shown>")
+	dumpedBytes           = []byte("<already dumped>")
 	invalidAngleBytes     = []byte("<invalid>")
 	openBracketBytes      = []byte("[")
 	closeBracketBytes     = []byte("]")
@@ -62,8 +77,89 @@ var (
 	closeMapBytes         = []byte("]")
 	lenEqualsBytes        = []byte("len=")
 	capEqualsBytes        = []byte("cap=")
+	truncatedBytes        = []byte("...(truncated, ")
+	bytesCloseParenBytes  = []byte(" bytes)")
+	moreOpenParenBytes    = []byte("... (")
+	moreElementsBytes     = []byte(" more elements)")
+	moreEntriesBytes      = []byte(" more entries)")
+	moreFieldsBytes       = []byte(" more fields)")
+	outputTruncatedBytes  = []byte("... (output truncated at ")
+	unwrapArrowBytes      = []byte(" -> ")
+	backquoteBytes        = []byte("`")
+	ifaceEqualsBytes      = []byte(" = ")
+	kindEqualsBytes       = []byte(" kind=")
+	countEqualsBytes      = []byte("count=")
+	recursiveMethodBytes  = []byte("<recursive method call> ")
+	embeddedBytes         = []byte("<embedded> ")
+	mathNaNBytes          = []byte("math.NaN()")
+	mathPosInfBytes       = []byte("math.Inf(1)")
+	mathNegInfBytes       = []byte("math.Inf(-1)")
+	complexOpenBytes      = []byte("complex(")
+	angleBytes            = []byte("∠")
 )
 
+// timeType is a reflect.Type representing a time.Time.  It is used to detect
+// time values so they can be displayed as a readable timestamp.
+var timeType = reflect.TypeOf(time.Time{})
+
+// knownTypes maps types whose internal fields are never useful to display to a
+// function which returns the readable form of a pointer to a value of the type.
+var knownTypes = map[reflect.Type]func(interface{}) string{
+	timeType: func(p interface{}) string {
+		return p.(*time.Time).Format(time.RFC3339Nano)
+	},
+	reflect.TypeOf(big.Int{}): func(p interface{}) string {
+		return p.(*big.Int).String()
+	},
+	reflect.TypeOf(big.Rat{}): func(p interface{}) string {
+		return p.(*big.Rat).String()
+	},
+	reflect.TypeOf(big.Float{}): func(p interface{}) string {
+		return p.(*big.Float).String()
+	},
+}
+
+// runeType is a reflect.Type representing a rune.  Since rune is an alias for
+// int32, it also represents an int32.
+var runeType = reflect.TypeOf(rune(0))
+
+// reflectValueType is a reflect.Type representing a reflect.Value.  It is used
+// to detect reflect.Values so the values they represent can be displayed
+// instead of their internals.
+var reflectValueType = reflect.TypeOf(reflect.Value{})
+
+// unpackReflectValue returns the value represented by the passed value, which
+// must be a reflect.Value.  As with handleMethods, unsafe is used, when it's
+// available, to access reflect.Values which are stored in unexported struct
+// fields.  The boolean return is false when the value can't be accessed.
+func unpackReflectValue(v reflect.Value) (reflect.Value, bool) {
+	if !v.CanInterface() {
+		if UnsafeDisabled {
+			return reflect.Value{}, false
+		}
+
+		v = unsafeReflectValue(v)
+		if !v.CanInterface() {
+			return reflect.Value{}, false
+		}
+	}
+	return v.Interface().(reflect.Value), true
+}
+
+// packagePathRE is a regular expression that matches the directories of a
+// package path which precede the package name in a type name, such as the
+// github.com/org/proj/internal/ of github.com/org/proj/internal/pkg.Thing.
+var packagePathRE = regexp.MustCompile(`[\w.~-]+(/[\w.~-]+)*/`)
+
+// shortTypeName returns the passed type name with the directories of all of
+// the package paths it contains removed, leaving only the package names.
+func shortTypeName(name string) string {
+	if !strings.Contains(name, "/") {
+		return name
+	}
+	return packagePathRE.ReplaceAllString(name, "")
+}
+
 // hexDigits is used to map a decimal value to a hex digit.
 var hexDigits = "0123456789abcdef"
 
@@ -77,12 +173,11 @@ func catchPanic(w io.Writer, v reflect.Value) {
 	}
 }
 
-// handleMethods attempts to call the Error and String methods on the underlying
-// type the passed reflect.Value represents and outputes the result to Writer w.
-//
-// It handles panics in any called methods by catching and displaying the error
-// as the formatted value.
-func handleMethods(cs *ConfigState, w io.Writer, v reflect.Value) (handled bool) {
+// methodReceiver returns a value which methods of the type the passed
+// reflect.Value represents can be invoked on via an interface, including
+// methods with a pointer receiver when the DisablePointerMethods option allows
+// it.  It returns false when no such value can be obtained.
+func methodReceiver(cs *ConfigState, v reflect.Value) (reflect.Value, bool) {
 	// We need an interface to check if the type implements the error or
 	// Stringer interface.  However, the reflect package won't give us an
 	// interface on certain things like unexported struct fields in order
@@ -91,10 +186,13 @@ func handleMethods(cs *ConfigState, w io.Writer, v reflect.Value) (handled bool)
 	// values.
 	if !v.CanInterface() {
 		if UnsafeDisabled {
-			return false
+			return v, false
 		}
 
 		v = unsafeReflectValue(v)
+		if !v.CanInterface() {
+			return v, false
+		}
 	}
 
 	// Choose whether or not to do error and Stringer interface lookups against
@@ -109,23 +207,281 @@ func handleMethods(cs *ConfigState, w io.Writer, v reflect.Value) (handled bool)
 	if v.CanAddr() {
 		v = v.Addr()
 	}
+	return v, true
+}
+
+// handleDumper attempts to call the SpewDump method on the underlying type the
+// passed reflect.Value represents, which lets the type output itself to Writer
+// w.  It returns whether or not the type implements the Dumper interface.
+func handleDumper(cs *ConfigState, w io.Writer, v reflect.Value) bool {
+	v, ok := methodReceiver(cs, v)
+	if !ok {
+		return false
+	}
+
+	dumper, ok := v.Interface().(Dumper)
+	if !ok {
+		return false
+	}
+	call, ok := cs.enterMethod(v)
+	if !ok {
+		w.Write(recursiveMethodBytes)
+		return false
+	}
+	defer cs.exitMethod(call)
+	defer catchPanic(w, v)
+	dumper.SpewDump(w, cs)
+	return true
+}
 
-	// Is it an error or Stringer?
-	switch iface := v.Interface().(type) {
-	case error:
+// handleFormatter calls the function registered with RegisterFormatter for the
+// type of the passed value, or a pointer to it when the value is addressable,
+// to output it to Writer w.  It returns whether or not a function was called.
+func handleFormatter(cs *ConfigState, w io.Writer, v reflect.Value) bool {
+	if len(cs.formatters) == 0 || !v.IsValid() {
+		return false
+	}
+
+	fn, ok := cs.formatters[v.Type()]
+	if !ok && v.CanAddr() {
+		if fn, ok = cs.formatters[reflect.PtrTo(v.Type())]; ok {
+			v = v.Addr()
+		}
+	}
+	if !ok {
+		return false
+	}
+	defer catchPanic(w, v)
+	fn(w, v, cs)
+	return true
+}
+
+// writeError outputs the message of the passed error to Writer w.  When the
+// UnwrapErrors option is set, it is followed by the messages of the errors it
+// wraps, as returned by an Unwrap method, each separated by an arrow.  Errors
+// which wrap multiple errors have the chain of each one output as a bracketed
+// list.
+func writeError(cs *ConfigState, w io.Writer, err error, depth int) {
+	w.Write([]byte(err.Error()))
+	if !cs.UnwrapErrors {
+		return
+	}
+
+	var wrapped []error
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			wrapped = append(wrapped, inner)
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if inner != nil {
+				wrapped = append(wrapped, inner)
+			}
+		}
+	}
+	if len(wrapped) == 0 {
+		return
+	}
+
+	w.Write(unwrapArrowBytes)
+	if cs.depthExceeded(depth + 1) {
+		w.Write(maxShortBytes)
+		return
+	}
+	if len(wrapped) == 1 {
+		writeError(cs, w, wrapped[0], depth+1)
+		return
+	}
+	w.Write(openBracketBytes)
+	for i, inner := range wrapped {
+		if i > 0 {
+			w.Write(commaSpaceBytes)
+		}
+		writeError(cs, w, inner, depth+1)
+	}
+	w.Write(closeBracketBytes)
+}
+
+// methodCall identifies a value which has its SpewDump, Error, String, or
+// MarshalText method invoked by the traversal tracking it.  Values are
+// identified by their type and address, so only pointers and addressable
+// values can be tracked.
+type methodCall struct {
+	t    reflect.Type
+	addr uintptr
+}
+
+var (
+	// errorType, stringerType, and textMarshalerType are reflect.Types
+	// representing the interfaces handleMethods invokes the methods of.
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// enterMethod marks the method of the passed receiver, as returned by
+// methodReceiver, as being invoked by the dump which owns the config state.
+// It returns false when the method is already being invoked, which means it
+// displays its own receiver with the config state it was passed.  Config
+// states which aren't tracking methods always return true.
+func (c *ConfigState) enterMethod(v reflect.Value) (methodCall, bool) {
+	var call methodCall
+	if c.methods == nil {
+		return call, true
+	}
+	switch {
+	case v.Kind() == reflect.Ptr && !v.IsNil():
+		call = methodCall{t: v.Type(), addr: v.Pointer()}
+	case v.CanAddr():
+		call = methodCall{t: reflect.PtrTo(v.Type()), addr: v.UnsafeAddr()}
+	default:
+		return call, true
+	}
+	if c.methods[call] {
+		return call, false
+	}
+	c.methods[call] = true
+	return call, true
+}
+
+// exitMethod marks the method of the passed call, as returned by enterMethod,
+// as finished.
+func (c *ConfigState) exitMethod(call methodCall) {
+	if call.t != nil {
+		delete(c.methods, call)
+	}
+}
+
+// invokedMethod returns the interface type of the first of the error, Stringer,
+// and, when the UseTextMarshaler option is set, TextMarshaler interfaces which
+// the passed type implements, or nil when there is none.  When the
+// OnlyDirectMethods option is set, methods promoted from embedded fields are
+// ignored.
+func invokedMethod(cs *ConfigState, t reflect.Type) reflect.Type {
+	for _, method := range []reflect.Type{errorType, stringerType, textMarshalerType} {
+		if method == textMarshalerType && !cs.UseTextMarshaler {
+			continue
+		}
+		if !t.Implements(method) {
+			continue
+		}
+		if cs.OnlyDirectMethods && isPromoted(t, method.Method(0).Name) {
+			continue
+		}
+		return method
+	}
+	return nil
+}
+
+// isPromoted returns whether or not the named method of the passed type is
+// promoted from one of its embedded fields rather than declared on the type.
+// Pointer types are treated the same as the type they point to when the
+// method has a value receiver.
+//
+// Reflection doesn't distinguish promoted methods from declared ones, so a
+// method is considered promoted when an embedded field has a method of the
+// same name and the method of the type is one of the wrappers generated by
+// the compiler for promoted methods.
+func isPromoted(t reflect.Type, name string) bool {
+	if t.Kind() == reflect.Ptr {
+		if _, ok := t.Elem().MethodByName(name); ok {
+			t = t.Elem()
+		}
+	}
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return false
+	}
+
+	embedded := false
+	for i := 0; i < st.NumField() && !embedded; i++ {
+		sf := st.Field(i)
+		if !sf.Anonymous {
+			continue
+		}
+		_, ok := sf.Type.MethodByName(name)
+		_, ptrOk := reflect.PtrTo(sf.Type).MethodByName(name)
+		embedded = ok || ptrOk
+	}
+	if !embedded {
+		return false
+	}
+
+	m, ok := t.MethodByName(name)
+	if !ok {
+		return false
+	}
+	fn := runtime.FuncForPC(m.Func.Pointer())
+	if fn == nil {
+		return true
+	}
+	file, _ := fn.FileLine(fn.Entry())
+	return file == "<autogenerated>"
+}
+
+// handleMethods attempts to call the Error and String methods on the underlying
+// type the passed reflect.Value represents and outputes the result to Writer w.
+//
+// It handles panics in any called methods by catching and displaying the error
+// as the formatted value.
+//
+// The methods in progress are tracked by the dump which owns the config state,
+// including dumps and formatters started with the config state it passes to
+// Dumper implementations.  A method which is invoked again on the same value
+// while it is in progress would otherwise recurse forever, so a note is output
+// instead and the value is displayed without invoking its methods.  Methods
+// which display their receiver through the top-level functions or the fmt
+// package start an unrelated traversal, so they can't be detected.
+func handleMethods(cs *ConfigState, w io.Writer, v reflect.Value) (handled bool) {
+	if cs.DisableMethodsForTypes[v.Type()] {
+		return false
+	}
+
+	v, ok := methodReceiver(cs, v)
+	if !ok {
+		return false
+	}
+
+	// Is it an error, Stringer, or TextMarshaler?  The dynamic type is used
+	// since the value may be an interface, such as a map key.
+	iface := v.Interface()
+	if iface == nil {
+		return false
+	}
+	method := invokedMethod(cs, reflect.TypeOf(iface))
+	if method == nil {
+		return false
+	}
+
+	// Guard against recursion now that there is a method to invoke.
+	call, ok := cs.enterMethod(v)
+	if !ok {
+		w.Write(recursiveMethodBytes)
+		return false
+	}
+	defer cs.exitMethod(call)
+
+	switch method {
+	case errorType:
+		iface := iface.(error)
 		defer catchPanic(w, v)
 		if cs.ContinueOnMethod {
 			w.Write(openParenBytes)
-			w.Write([]byte(iface.Error()))
+			writeError(cs, w, iface, 0)
 			w.Write(closeParenBytes)
 			w.Write(spaceBytes)
 			return false
 		}
 
-		w.Write([]byte(iface.Error()))
+		writeError(cs, w, iface, 0)
 		return true
 
-	case fmt.Stringer:
+	case stringerType:
+		iface := iface.(fmt.Stringer)
 		defer catchPanic(w, v)
 		if cs.ContinueOnMethod {
 			w.Write(openParenBytes)
@@ -136,10 +492,321 @@ func handleMethods(cs *ConfigState, w io.Writer, v reflect.Value) (handled bool)
 		}
 		w.Write([]byte(iface.String()))
 		return true
+
+	case textMarshalerType:
+		iface := iface.(encoding.TextMarshaler)
+		defer catchPanic(w, v)
+		text, err := iface.MarshalText()
+		if err != nil {
+			return false
+		}
+		if cs.ContinueOnMethod {
+			w.Write(openParenBytes)
+			w.Write(text)
+			w.Write(closeParenBytes)
+			w.Write(spaceBytes)
+			return false
+		}
+		w.Write(text)
+		return true
+	}
+	return false
+}
+
+// formatKey returns the passed map key formatted the same as the Formatter
+// would display it.
+func formatKey(cs *ConfigState, key reflect.Value) string {
+	if !key.CanInterface() {
+		key = unsafeReflectValue(key)
+	}
+	if key.CanInterface() {
+		return fmt.Sprint(newFormatter(cs, key.Interface()))
+	}
+	return key.String()
+}
+
+// visibleFields returns the indices of the fields of the passed struct type
+// which should be displayed.  Fields tagged with spew:"-" are skipped, which
+// mirrors the convention used by the encoding/json package, as are unexported
+// fields when the ExportedOnly option is set.  The fields are
+// in declaration order unless the SortFields option is set, in which case they
+// are sorted by the name which is displayed for them.
+func visibleFields(cs *ConfigState, vt reflect.Type) []int {
+	numFields := vt.NumField()
+	fields := make([]int, 0, numFields)
+	for i := 0; i < numFields; i++ {
+		sf := vt.Field(i)
+		if sf.Tag.Get("spew") == "-" || cs.ExportedOnly && sf.PkgPath != "" {
+			continue
+		}
+		fields = append(fields, i)
+	}
+	if cs.SortFields {
+		sort.Stable(&fieldsSorter{vt: vt, fields: fields})
+	}
+	return fields
+}
+
+// isNumber returns whether or not the passed kind is an integer, float, or
+// complex number kind.  Uintptrs are excluded since they are displayed as
+// addresses.
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64,
+		reflect.Complex64, reflect.Complex128:
+		return true
 	}
 	return false
 }
 
+// refAddr returns the address of the data referenced by the passed value when
+// it is a non-empty slice or map and 0 otherwise.  Unlike other values, slices
+// and maps are able to contain themselves, such as via interface elements, so
+// the data they reference must be tracked like pointers in order to detect
+// circular references.
+func refAddr(v reflect.Value) uintptr {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		if !v.IsNil() && v.Len() > 0 {
+			return v.Pointer()
+		}
+	}
+	return 0
+}
+
+// isZero returns whether or not the passed value is the zero value for its
+// type.  Nil pointers, interfaces, maps, slices, channels, and funcs, empty
+// strings, zero numbers, and false are zero, as are arrays and structs which
+// consist entirely of zero values.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool:
+		return !v.Bool()
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return v.Int() == 0
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uint, reflect.Uintptr:
+		return v.Uint() == 0
+
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex() == 0
+
+	case reflect.String:
+		return v.Len() == 0
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isZero(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isZero(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+		reflect.Ptr, reflect.Slice, reflect.UnsafePointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// nonZeroFields returns the passed indices of fields of the struct v with
+// those whose values are zero removed.
+func nonZeroFields(v reflect.Value, fields []int) []int {
+	nonZero := fields[:0]
+	for _, i := range fields {
+		if !isZero(v.Field(i)) {
+			nonZero = append(nonZero, i)
+		}
+	}
+	return nonZero
+}
+
+// nonZeroKeys returns the passed keys of the map v with those whose values are
+// zero removed.
+func nonZeroKeys(v reflect.Value, keys []reflect.Value) []reflect.Value {
+	nonZero := keys[:0]
+	for _, key := range keys {
+		if !isZero(v.MapIndex(key)) {
+			nonZero = append(nonZero, key)
+		}
+	}
+	return nonZero
+}
+
+// omitted returns whether or not the passed value located at the passed path
+// is omitted by the OmitFunc option.  Values stored in interfaces are passed
+// to it unpacked, the same as they are displayed.
+func omitted(cs *ConfigState, path string, v reflect.Value) bool {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return cs.OmitFunc(path, v)
+}
+
+// shownIndices returns the indices of the elements of the array or slice v,
+// which is located at the passed path, that are not omitted by the OmitFunc
+// option.
+func shownIndices(cs *ConfigState, path string, v reflect.Value) []int {
+	shown := make([]int, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if !omitted(cs, indexPath(path, i), v.Index(i)) {
+			shown = append(shown, i)
+		}
+	}
+	return shown
+}
+
+// shownKeys returns the passed keys of the map v, which is located at the
+// passed path, with those whose entries are omitted by the OmitFunc option
+// removed.
+func shownKeys(cs *ConfigState, path string, v reflect.Value, keys []reflect.Value) []reflect.Value {
+	shown := keys[:0]
+	for _, key := range keys {
+		if !omitted(cs, keyPath(cs, path, key), v.MapIndex(key)) {
+			shown = append(shown, key)
+		}
+	}
+	return shown
+}
+
+// shownFields returns the passed indices of fields of the struct v, which is
+// located at the passed path, with those omitted by the OmitFunc option
+// removed.
+func shownFields(cs *ConfigState, path string, v reflect.Value, fields []int) []int {
+	vt := v.Type()
+	shown := fields[:0]
+	for _, i := range fields {
+		if !omitted(cs, fieldPath(path, fieldName(vt.Field(i))), v.Field(i)) {
+			shown = append(shown, i)
+		}
+	}
+	return shown
+}
+
+// fieldsSorter implements sort.Interface to allow the indices of struct fields
+// to be sorted by the name which is displayed for each field.  Embedded fields
+// are named after their type, so they sort by their type name.
+type fieldsSorter struct {
+	vt     reflect.Type
+	fields []int
+}
+
+// Len returns the number of fields in the list.  It is part of the
+// sort.Interface implementation.
+func (s *fieldsSorter) Len() int {
+	return len(s.fields)
+}
+
+// Swap swaps the fields at the passed indices.  It is part of the
+// sort.Interface implementation.
+func (s *fieldsSorter) Swap(i, j int) {
+	s.fields[i], s.fields[j] = s.fields[j], s.fields[i]
+}
+
+// Less returns whether the name of the field at index i should sort before the
+// name of the field at index j.  It is part of the sort.Interface
+// implementation.
+func (s *fieldsSorter) Less(i, j int) bool {
+	return fieldName(s.vt.Field(s.fields[i])) < fieldName(s.vt.Field(s.fields[j]))
+}
+
+// fieldName returns the name to display for the passed struct field.  A name
+// given by the spew struct tag takes precedence over the name of the field.
+// The tag follows the comma separated syntax of the encoding/json package, so
+// any options after the name are ignored, and spew:"-," names a field "-".
+func fieldName(vtf reflect.StructField) string {
+	name := vtf.Tag.Get("spew")
+	if i := strings.Index(name, ","); i >= 0 {
+		name = name[:i]
+	}
+	if name == "" {
+		return vtf.Name
+	}
+	return name
+}
+
+// fieldPath returns the path to the named struct field within the value at
+// the passed path.
+func fieldPath(path, name string) string {
+	return path + "." + name
+}
+
+// indexPath returns the path to the element at index i of the array or slice
+// at the passed path.
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+// keyPath returns the path to the entry for the passed key within the map at
+// the passed path.  String keys are quoted while other keys are formatted the
+// same as the Formatter would display them.
+func keyPath(cs *ConfigState, path string, key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return path + "[" + strconv.Quote(key.String()) + "]"
+	}
+	return path + "[" + formatKey(cs, key) + "]"
+}
+
+// handleKnownTypes outputs the passed value in a readable form to Writer w
+// when it is one of the types in knownTypes and reports whether or not it did
+// so.  This ensures these values are readable even when method invocation is
+// disabled since their internal fields are never what a debugging user wants
+// to see.  Time values are not handled when the DisableTimeFormat option is
+// set.
+func handleKnownTypes(cs *ConfigState, w io.Writer, v reflect.Value) bool {
+	vt := v.Type()
+	format, ok := knownTypes[vt]
+	if !ok || (vt == timeType && cs.DisableTimeFormat) {
+		return false
+	}
+	if !v.CanInterface() {
+		v = unsafeReflectValue(v)
+		if !v.CanInterface() {
+			return false
+		}
+	}
+
+	// The math/big types only provide methods with pointer receivers, so
+	// format a pointer to a copy of the value which is safe since the copy
+	// is only read.
+	pv := reflect.New(vt)
+	pv.Elem().Set(v)
+	w.Write([]byte(format(pv.Interface())))
+	return true
+}
+
+// runeChar returns the passed value as a quoted character along with whether
+// or not it is a valid code point.  Code points which are not printable are
+// escaped in the form '\uXXXX' or '\UXXXXXXXX'.
+func runeChar(val int64) (string, bool) {
+	r := rune(val)
+	if int64(r) != val || !utf8.ValidRune(r) {
+		return "", false
+	}
+	if unicode.IsPrint(r) {
+		return strconv.QuoteRune(r), true
+	}
+	if r <= 0xffff {
+		return fmt.Sprintf(`'\u%04x'`, r), true
+	}
+	return fmt.Sprintf(`'\U%08x'`, r), true
+}
+
 // printBool outputs a boolean value as true or false to Writer w.
 func printBool(w io.Writer, val bool) {
 	if val {
@@ -149,37 +816,217 @@ func printBool(w io.Writer, val bool) {
 	}
 }
 
-// printInt outputs a signed integer value to Writer w.
+// basePrefix returns the prefix which identifies the passed base in integer
+// output.  Bases without a conventional prefix, including 10, have none.
+func basePrefix(base int) string {
+	switch base {
+	case 2:
+		return "0b"
+	case 8:
+		return "0o"
+	case 16:
+		return "0x"
+	}
+	return ""
+}
+
+// printInt outputs a signed integer value in the passed base to Writer w.
+// Binary, octal, and hexadecimal values are prefixed with 0b, 0o, and 0x,
+// respectively.
 func printInt(w io.Writer, val int64, base int) {
-	w.Write([]byte(strconv.FormatInt(val, base)))
+	prefix := basePrefix(base)
+	if prefix == "" {
+		w.Write([]byte(strconv.FormatInt(val, base)))
+		return
+	}
+
+	var buf []byte
+	uval := uint64(val)
+	if val < 0 {
+		buf = append(buf, '-')
+		uval = -uval
+	}
+	buf = append(buf, prefix...)
+	w.Write(strconv.AppendUint(buf, uval, base))
 }
 
-// printUint outputs an unsigned integer value to Writer w.
+// printUint outputs an unsigned integer value in the passed base to Writer w.
+// Binary, octal, and hexadecimal values are prefixed with 0b, 0o, and 0x,
+// respectively.
 func printUint(w io.Writer, val uint64, base int) {
-	w.Write([]byte(strconv.FormatUint(val, base)))
+	w.Write([]byte(basePrefix(base) + strconv.FormatUint(val, base)))
 }
 
 // printFloat outputs a floating point value using the specified precision,
-// which is expected to be 32 or 64bit, to Writer w.
-func printFloat(w io.Writer, val float64, precision int) {
-	w.Write([]byte(strconv.FormatFloat(val, 'g', -1, precision)))
+// which is expected to be 32 or 64bit, to Writer w.  The format and number of
+// digits are determined by the FloatFormat and FloatPrecision options of the
+// passed config state, which may be nil to use the defaults.
+func printFloat(w io.Writer, val float64, precision int, cs *ConfigState) {
+	if expr, ok := floatSpecial(val); ok && cs.floatSpecials() {
+		w.Write(expr)
+		return
+	}
+	format, digits := cs.floatFormat()
+	w.Write([]byte(strconv.FormatFloat(val, format, digits, precision)))
+}
+
+// floatSpecial returns the Go expression which results in the passed floating
+// point value when it is NaN or infinite.
+func floatSpecial(val float64) ([]byte, bool) {
+	switch {
+	case math.IsNaN(val):
+		return mathNaNBytes, true
+	case math.IsInf(val, 1):
+		return mathPosInfBytes, true
+	case math.IsInf(val, -1):
+		return mathNegInfBytes, true
+	}
+	return nil, false
 }
 
 // printComplex outputs a complex value using the specified float precision
-// for the real and imaginary parts to Writer w.
-func printComplex(w io.Writer, c complex128, floatPrecision int) {
+// for the real and imaginary parts to Writer w.  The format and number of
+// digits are determined the same as for printFloat.  Values are output by their
+// magnitude and phase angle instead when the ComplexFormat option is set to
+// ComplexPolar.  Values with a NaN or infinite part are output as a call to
+// complex when the FloatSpecialAsString option is set since they can't be
+// expressed as a literal.
+func printComplex(w io.Writer, c complex128, floatPrecision int, cs *ConfigState) {
+	_, realSpecial := floatSpecial(real(c))
+	_, imagSpecial := floatSpecial(imag(c))
+	if (realSpecial || imagSpecial) && cs.floatSpecials() {
+		w.Write(complexOpenBytes)
+		printFloat(w, real(c), floatPrecision, cs)
+		w.Write(commaSpaceBytes)
+		printFloat(w, imag(c), floatPrecision, cs)
+		w.Write(closeParenBytes)
+		return
+	}
+
+	format, digits := cs.floatFormat()
+	if cs.polarComplex() {
+		w.Write(openParenBytes)
+		w.Write([]byte(strconv.FormatFloat(cmplx.Abs(c), format, digits, floatPrecision)))
+		w.Write(angleBytes)
+		w.Write([]byte(strconv.FormatFloat(cmplx.Phase(c), format, digits, floatPrecision)))
+		w.Write(closeParenBytes)
+		return
+	}
+
 	r := real(c)
 	w.Write(openParenBytes)
-	w.Write([]byte(strconv.FormatFloat(r, 'g', -1, floatPrecision)))
+	w.Write([]byte(strconv.FormatFloat(r, format, digits, floatPrecision)))
 	i := imag(c)
 	if i >= 0 {
 		w.Write(plusBytes)
 	}
-	w.Write([]byte(strconv.FormatFloat(i, 'g', -1, floatPrecision)))
+	w.Write([]byte(strconv.FormatFloat(i, format, digits, floatPrecision)))
 	w.Write(iBytes)
 	w.Write(closeParenBytes)
 }
 
+// truncateString returns the passed string limited to at most max runes along
+// with whether or not it was truncated.  The string is always cut on a rune
+// boundary so multibyte characters are never split.  A max of zero or less
+// means there is no limit.
+func truncateString(s string, max int) (string, bool) {
+	if max <= 0 {
+		return s, false
+	}
+	runes := 0
+	for i := range s {
+		if runes == max {
+			return s[:i], true
+		}
+		runes++
+	}
+	return s, false
+}
+
+// isPrintableText returns whether the passed bytes are valid UTF-8 made up
+// entirely of printable runes.  Control characters, including newlines and
+// tabs, are not considered printable.
+func isPrintableText(buf []byte) bool {
+	if !utf8.Valid(buf) {
+		return false
+	}
+	for len(buf) > 0 {
+		r, size := utf8.DecodeRune(buf)
+		if !unicode.IsPrint(r) {
+			return false
+		}
+		buf = buf[size:]
+	}
+	return true
+}
+
+// quoteString returns the passed string as a double-quoted Go string literal.
+// When raw is set, only control characters, invalid UTF-8, double quotes, and
+// backslashes are escaped, with the bytes of control characters and invalid
+// UTF-8 escaped as \xNN, so any other text is kept intact.  Otherwise it is
+// quoted by strconv.Quote.
+func quoteString(s string, raw bool) string {
+	if !raw {
+		return strconv.Quote(s)
+	}
+
+	buf := make([]byte, 0, len(s)+2)
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1, unicode.IsControl(r):
+			for j := i; j < i+size; j++ {
+				buf = append(buf, '\\', 'x', hexDigits[s[j]>>4],
+					hexDigits[s[j]&0x0f])
+			}
+		case r == '"', r == '\\':
+			buf = append(buf, '\\', byte(r))
+		default:
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return string(append(buf, '"'))
+}
+
+// printTruncated outputs the marker used to indicate a string was truncated
+// along with its full length in bytes to Writer w.
+func printTruncated(w io.Writer, fullLen int) {
+	w.Write(truncatedBytes)
+	printInt(w, int64(fullLen), 10)
+	w.Write(bytesCloseParenBytes)
+}
+
+// printMore outputs the marker used to indicate that n items of a collection
+// were omitted to Writer w.  The what parameter describes the kind of items,
+// such as elements or entries.
+func printMore(w io.Writer, n int, what []byte) {
+	w.Write(moreOpenParenBytes)
+	printInt(w, int64(n), 10)
+	w.Write(what)
+}
+
+// limitEntries returns the number of entries to display for a collection with
+// numEntries entries given the configured maximum.  A max of zero or less
+// means there is no limit.
+func limitEntries(numEntries, max int) int {
+	if max > 0 && numEntries > max {
+		return max
+	}
+	return numEntries
+}
+
+// printPtrOrNil outputs the passed pointer the same as printHexPtr except that
+// a null pointer is output as the nil marker of the passed config state.
+func printPtrOrNil(cs *ConfigState, w io.Writer, p uintptr) {
+	if p == 0 {
+		w.Write(cs.nilMarker())
+		return
+	}
+	printHexPtr(w, p)
+}
+
 // printHexPtr outputs a uintptr formatted as hexadecimal with a leading '0x'
 // prefix to Writer w.
 func printHexPtr(w io.Writer, p uintptr) {
@@ -289,10 +1136,24 @@ func (s *valuesSorter) Swap(i, j int) {
 	}
 }
 
+// ptrPair is a pair of pointers which are being compared by valueSortLess.
+type ptrPair struct {
+	a, b uintptr
+}
+
 // valueSortLess returns whether the first value should sort before the second
 // value.  It is used by valueSorter.Less as part of the sort.Interface
 // implementation.
 func valueSortLess(a, b reflect.Value) bool {
+	return valueLess(a, b, nil)
+}
+
+// valueLess implements valueSortLess.  The visited map tracks the pairs of
+// pointers whose pointed-to values are being compared so values which refer
+// back to themselves, such as the nodes of a circular list, are ordered by
+// their addresses once a pair repeats instead of recursing forever.  It is
+// allocated once the first pair of pointers is reached.
+func valueLess(a, b reflect.Value, visited map[ptrPair]bool) bool {
 	switch a.Kind() {
 	case reflect.Bool:
 		return !a.Bool() && b.Bool()
@@ -302,21 +1163,66 @@ func valueSortLess(a, b reflect.Value) bool {
 		return a.Uint() < b.Uint()
 	case reflect.Float32, reflect.Float64:
 		return a.Float() < b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		// Order by the real part and then by the imaginary part.
+		ac, bc := a.Complex(), b.Complex()
+		if real(ac) != real(bc) {
+			return real(ac) < real(bc)
+		}
+		return imag(ac) < imag(bc)
 	case reflect.String:
 		return a.String() < b.String()
 	case reflect.Uintptr:
 		return a.Uint() < b.Uint()
+	case reflect.Ptr:
+		// Order by the pointed-to values with nil pointers first and only
+		// fall back to the addresses when the pointed-to values are the same
+		// so the order doesn't depend on where they happen to be allocated.
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && !b.IsNil()
+		}
+		pair := ptrPair{a.Pointer(), b.Pointer()}
+		if visited[pair] {
+			return pair.a < pair.b
+		}
+		if visited == nil {
+			visited = make(map[ptrPair]bool)
+		}
+		visited[pair] = true
+		defer delete(visited, pair)
+
+		ae, be := a.Elem(), b.Elem()
+		if valueLess(ae, be, visited) {
+			return true
+		}
+		if valueLess(be, ae, visited) {
+			return false
+		}
+		return pair.a < pair.b
 	case reflect.Array:
-		// Compare the contents of both arrays.
-		l := a.Len()
-		for i := 0; i < l; i++ {
-			av := a.Index(i)
-			bv := b.Index(i)
-			if av.Interface() == bv.Interface() {
-				continue
+		// Compare the contents of both arrays element by element.
+		for i := 0; i < a.Len(); i++ {
+			av, bv := a.Index(i), b.Index(i)
+			if valueLess(av, bv, visited) {
+				return true
+			}
+			if valueLess(bv, av, visited) {
+				return false
+			}
+		}
+		return false
+	case reflect.Struct:
+		// Compare the contents of both structs field by field.
+		for i := 0; i < a.NumField(); i++ {
+			af, bf := a.Field(i), b.Field(i)
+			if valueLess(af, bf, visited) {
+				return true
+			}
+			if valueLess(bf, af, visited) {
+				return false
 			}
-			return valueSortLess(av, bv)
 		}
+		return false
 	}
 	return a.String() < b.String()
 }
@@ -332,10 +1238,29 @@ func (s *valuesSorter) Less(i, j int) bool {
 
 // sortValues is a sort function that handles both native types and any type that
 // can be converted to error or Stringer.  Other inputs are sorted according to
-// their Value.String() value to ensure display stability.
+// their Value.String() value to ensure display stability.  The values are
+// left in their original order when sorting them panics, such as when values
+// of different kinds are compared, so a sorting failure results in unsorted
+// output rather than a crash.
 func sortValues(values []reflect.Value, cs *ConfigState) {
 	if len(values) == 0 {
 		return
 	}
+
+	original := append([]reflect.Value(nil), values...)
+	defer func() {
+		if r := recover(); r != nil {
+			copy(values, original)
+		}
+	}()
 	sort.Sort(newValuesSorter(values, cs))
 }
+
+// SortValues sorts the passed values in place into the same order the SortKeys
+// option uses for map keys according to the global Config.  This allows
+// packages which build their own output on top of spew to order values
+// consistently with it.  See ConfigState.SortValues to use a different
+// configuration.
+func SortValues(values []reflect.Value) {
+	sortValues(values, &Config)
+}
@@ -18,7 +18,10 @@ package spew_test
 
 import (
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -42,6 +45,56 @@ func (s *pstringer) String() string {
 	return "stringer " + string(*s)
 }
 
+// pstringerWrap is used to test that Stringer interfaces which are promoted
+// through an embedded pointer are invoked on the correct memory.
+type pstringerWrap struct {
+	*pstringer
+}
+
+// stringerWrap and stringerOverride are used to test distinguishing Stringer
+// interfaces which are promoted from an embedded field from those declared on
+// the type.
+type stringerWrap struct {
+	stringer
+	N int
+}
+
+type stringerOverride struct {
+	stringer
+}
+
+func (s stringerOverride) String() string {
+	return "override"
+}
+
+// tagSkip and tagSkipLast are used to test skipping struct fields via the
+// spew struct tag.
+type tagSkip struct {
+	A      int
+	secret string `spew:"-"`
+	B      int
+}
+type tagSkipLast struct {
+	A      int
+	Hidden []int `spew:"-"`
+}
+
+// tagRename is used to test renaming struct fields via the spew struct tag.
+type tagRename struct {
+	UserID int    `spew:"user_id"`
+	name   string `spew:"displayName,omitempty"`
+	Dash   int    `spew:"-,"`
+	Plain  int    `spew:",omitempty"`
+}
+
+// redactReq is used to test redacting values via the Redact callback.
+type redactReq struct {
+	User     string
+	Password string
+	Tokens   map[string]string
+	Keys     []int
+}
+
 // xref1 and xref2 are cross referencing structs for testing circular reference
 // detection.
 type xref1 struct {
@@ -82,6 +135,60 @@ func (p panicer) String() string {
 	panic("test panic")
 }
 
+// selfDumper is used to test Dumpers which dump the next value in a list with
+// the config they are passed are not invoked recursively when the list refers
+// back to them.
+type selfDumper struct {
+	N    int
+	Next *selfDumper
+}
+
+func (s *selfDumper) SpewDump(w io.Writer, cfg *spew.ConfigState) {
+	fmt.Fprintf(w, "N=%d ", s.N)
+	cfg.Fdump(w, s.Next)
+}
+
+// childSprinter is used to test Stringers which display other values of the
+// same type with spew are invoked for each of them.
+type childSprinter struct {
+	N     int
+	Child *childSprinter
+}
+
+func (c childSprinter) String() string {
+	if c.Child == nil {
+		return fmt.Sprint(c.N)
+	}
+	return fmt.Sprintf("%d>%s", c.N, spew.Sprint(*c.Child))
+}
+
+// ringDumper is used to test Dumper interface invocation.  It also implements
+// the Stringer interface to test the Dumper interface takes precedence.
+type ringDumper struct {
+	Items []int
+	Head  int
+}
+
+// SpewDump implements the Dumper interface by summarizing the ring.
+func (r ringDumper) SpewDump(w io.Writer, cfg *spew.ConfigState) {
+	fmt.Fprintf(w, "ring of %d, head %d", len(r.Items), r.Head)
+}
+
+func (r ringDumper) String() string {
+	return "ring"
+}
+
+// textMarshaler is used to test encoding.TextMarshaler interface invocation.
+// Negative values fail to marshal.
+type textMarshaler int
+
+func (t textMarshaler) MarshalText() ([]byte, error) {
+	if t < 0 {
+		return nil, fmt.Errorf("negative")
+	}
+	return []byte(fmt.Sprintf("text %d", int(t))), nil
+}
+
 // customError is used to test custom error interface invocation.
 type customError int
 
@@ -89,6 +196,46 @@ func (e customError) Error() string {
 	return fmt.Sprintf("error: %d", int(e))
 }
 
+// nilError is used to test displaying a nil pointer stored in an error
+// interface, which makes the interface itself non-nil.
+type nilError struct{}
+
+func (e *nilError) Error() string {
+	return "nil error"
+}
+
+// findError returns a typed nil *nilError as an error, which is the classic
+// mistake that results in a non-nil error.
+func findError() error {
+	var err *nilError
+	return err
+}
+
+// wrapError and multiError are used to test displaying the chain of wrapped
+// errors.
+type wrapError struct {
+	Msg string
+	Err error
+}
+
+func (e wrapError) Error() string {
+	return e.Msg
+}
+
+func (e wrapError) Unwrap() error {
+	return e.Err
+}
+
+type multiError []error
+
+func (e multiError) Error() string {
+	return "multi"
+}
+
+func (e multiError) Unwrap() []error {
+	return e
+}
+
 // stringizeWants converts a slice of wanted test output into a format suitable
 // for a test error message.
 func stringizeWants(wants []string) string {
@@ -126,6 +273,20 @@ type unsortableStruct struct {
 	x int
 }
 
+// cycleNode is a linked list node used to test sorting keys which are part of
+// a circular list.
+type cycleNode struct {
+	N    int
+	Next *cycleNode
+}
+
+// selfKey is a struct which refers to itself used to test sorting struct map
+// keys which are self-referential.
+type selfKey struct {
+	N    int
+	Self *selfKey
+}
+
 type sortTestCase struct {
 	input    []reflect.Value
 	expected []reflect.Value
@@ -141,7 +302,7 @@ func helpTestSortValues(tests []sortTestCase, cs *spew.ConfigState, t *testing.T
 	}
 
 	for _, test := range tests {
-		spew.SortValues(test.input, cs)
+		cs.SortValues(test.input)
 		// reflect.DeepEqual cannot really make sense of reflect.Value,
 		// probably because of all the pointer tricks. For instance,
 		// v(2.0) != v(2.0) on a 32-bits system. Turn them into interface{}
@@ -165,6 +326,11 @@ func TestSortValues(t *testing.T) {
 	embedA := v(embed{"a"})
 	embedB := v(embed{"b"})
 	embedC := v(embed{"c"})
+	one, two, three, fiveA, fiveB := 1, 2, 3, 5, 5
+	pFiveA, pFiveB := &fiveA, &fiveB
+	if reflect.ValueOf(pFiveB).Pointer() < reflect.ValueOf(pFiveA).Pointer() {
+		pFiveA, pFiveB = pFiveB, pFiveA
+	}
 	tests := []sortTestCase{
 		// No values.
 		{
@@ -191,6 +357,11 @@ func TestSortValues(t *testing.T) {
 			[]reflect.Value{v(2.0), v(1.0), v(3.0)},
 			[]reflect.Value{v(1.0), v(2.0), v(3.0)},
 		},
+		// Complexes.
+		{
+			[]reflect.Value{v(2 + 1i), v(1 + 2i), v(2 - 1i), v(complex64(1 + 1i))},
+			[]reflect.Value{v(complex64(1 + 1i)), v(1 + 2i), v(2 - 1i), v(2 + 1i)},
+		},
 		// Strings.
 		{
 			[]reflect.Value{b, a, c},
@@ -206,22 +377,41 @@ func TestSortValues(t *testing.T) {
 			[]reflect.Value{v(uintptr(2)), v(uintptr(1)), v(uintptr(3))},
 			[]reflect.Value{v(uintptr(1)), v(uintptr(2)), v(uintptr(3))},
 		},
+		// Pointers.
+		{
+			[]reflect.Value{v(pFiveB), v(&two), v((*int)(nil)), v(pFiveA), v(&three), v(&one)},
+			[]reflect.Value{v((*int)(nil)), v(&one), v(&two), v(&three), v(pFiveA), v(pFiveB)},
+		},
 		// SortableStructs.
 		{
-			// Note: not sorted - DisableMethods is set.
-			[]reflect.Value{v(sortableStruct{2}), v(sortableStruct{1}), v(sortableStruct{3})},
+			// Note: sorted by field since DisableMethods is set.
 			[]reflect.Value{v(sortableStruct{2}), v(sortableStruct{1}), v(sortableStruct{3})},
+			[]reflect.Value{v(sortableStruct{1}), v(sortableStruct{2}), v(sortableStruct{3})},
 		},
 		// UnsortableStructs.
 		{
-			// Note: not sorted - SpewKeys is false.
-			[]reflect.Value{v(unsortableStruct{2}), v(unsortableStruct{1}), v(unsortableStruct{3})},
 			[]reflect.Value{v(unsortableStruct{2}), v(unsortableStruct{1}), v(unsortableStruct{3})},
+			[]reflect.Value{v(unsortableStruct{1}), v(unsortableStruct{2}), v(unsortableStruct{3})},
 		},
-		// Invalid.
+		// Structs with unexported string fields.
 		{
 			[]reflect.Value{embedB, embedA, embedC},
-			[]reflect.Value{embedB, embedA, embedC},
+			[]reflect.Value{embedA, embedB, embedC},
+		},
+		// Structs with multiple fields.
+		{
+			[]reflect.Value{v(struct{ A, B int }{2, 1}), v(struct{ A, B int }{1, 2}), v(struct{ A, B int }{1, 1})},
+			[]reflect.Value{v(struct{ A, B int }{1, 1}), v(struct{ A, B int }{1, 2}), v(struct{ A, B int }{2, 1})},
+		},
+		// Incomparable kinds fall back to their stringified form.
+		{
+			[]reflect.Value{v([1][]int{{2}}), v([1][]int{{1}})},
+			[]reflect.Value{v([1][]int{{2}}), v([1][]int{{1}})},
+		},
+		// Mixed kinds whose comparison panics keep their original order.
+		{
+			[]reflect.Value{v(3), v(2), a, v(1)},
+			[]reflect.Value{v(3), v(2), a, v(1)},
 		},
 	}
 	cs := spew.ConfigState{DisableMethods: true, SpewKeys: false}
@@ -254,9 +444,9 @@ func TestSortValuesWithMethods(t *testing.T) {
 		},
 		// UnsortableStructs.
 		{
-			// Note: not sorted - SpewKeys is false.
-			[]reflect.Value{v(unsortableStruct{2}), v(unsortableStruct{1}), v(unsortableStruct{3})},
+			// Note: sorted by field since SpewKeys is false.
 			[]reflect.Value{v(unsortableStruct{2}), v(unsortableStruct{1}), v(unsortableStruct{3})},
+			[]reflect.Value{v(unsortableStruct{1}), v(unsortableStruct{2}), v(unsortableStruct{3})},
 		},
 	}
 	cs := spew.ConfigState{DisableMethods: false, SpewKeys: false}
@@ -296,3 +486,128 @@ func TestSortValuesWithSpew(t *testing.T) {
 	cs := spew.ConfigState{DisableMethods: true, SpewKeys: true}
 	helpTestSortValues(tests, &cs, t)
 }
+
+// TestSortValuesGlobal ensures the top-level SortValues function sorts values
+// according to the global Config.
+func TestSortValuesGlobal(t *testing.T) {
+	v := reflect.ValueOf
+	values := []reflect.Value{v(3), v(1), v(2)}
+	spew.SortValues(values)
+	for i, want := range []int{1, 2, 3} {
+		if got := values[i].Interface(); got != want {
+			t.Errorf("SortValues #%d got: %v want: %v", i, got, want)
+		}
+	}
+}
+
+// TestSortValuesCycle ensures sorting pointers to values which refer back to
+// each other terminates and orders them consistently.
+func TestSortValuesCycle(t *testing.T) {
+	a, b := &cycleNode{N: 1}, &cycleNode{N: 1}
+	a.Next, b.Next = b, a
+	want := []*cycleNode{a, b}
+	if reflect.ValueOf(b).Pointer() < reflect.ValueOf(a).Pointer() {
+		want = []*cycleNode{b, a}
+	}
+
+	v := reflect.ValueOf
+	values := []reflect.Value{v(want[1]), v(want[0])}
+	spew.SortValues(values)
+	for i := range want {
+		if got := values[i].Interface(); got != want[i] {
+			t.Errorf("SortValues #%d got: %p want: %p", i, got, want[i])
+		}
+	}
+
+	// Dumping and diffing maps keyed by the nodes sort the same keys.
+	cs := spew.ConfigState{Indent: " ", SortKeys: true, DisablePointerAddresses: true}
+	m := map[*cycleNode]int{a: 1, b: 2}
+	if s := cs.Sdump(m); !strings.Contains(s, "(len=2)") {
+		t.Errorf("Sdump of cyclic keys got: %q", s)
+	}
+	m2 := map[*cycleNode]int{a: 1, b: 3}
+	if d := cs.Diff(m, m2); d == "" {
+		t.Errorf("Diff of cyclic keys got no differences")
+	}
+}
+
+// TestSortValuesSelfKey ensures sorting struct values which point back to
+// themselves terminates and orders them by their fields first.
+func TestSortValuesSelfKey(t *testing.T) {
+	a, b, c := &selfKey{N: 2}, &selfKey{N: 1}, &selfKey{N: 1}
+	a.Self, b.Self, c.Self = a, b, c
+	second, third := *b, *c
+	if reflect.ValueOf(c).Pointer() < reflect.ValueOf(b).Pointer() {
+		second, third = *c, *b
+	}
+
+	v := reflect.ValueOf
+	values := []reflect.Value{v(*a), v(third), v(second)}
+	spew.SortValues(values)
+	for i, want := range []selfKey{second, third, *a} {
+		if got := values[i].Interface(); got != want {
+			t.Errorf("SortValues #%d got: %v want: %v", i, got, want)
+		}
+	}
+
+	cs := spew.ConfigState{Indent: " ", SortKeys: true, DisablePointerAddresses: true}
+	m := map[selfKey]int{*a: 1, *b: 2, *c: 3}
+	if s := cs.Sdump(m); !strings.Contains(s, "(len=3)") {
+		t.Errorf("Sdump of self-referential keys got: %q", s)
+	}
+}
+
+// TestRecursiveMethods ensures methods which display their own receiver with
+// the config they are passed are not invoked recursively while methods which
+// display other values of the same type are.
+func TestRecursiveMethods(t *testing.T) {
+	self := &selfDumper{N: 1}
+	self.Next = self
+	list := &selfDumper{N: 1, Next: &selfDumper{N: 2}}
+	var chain *childSprinter
+	for n := 12; n > 0; n-- {
+		chain = &childSprinter{N: n, Child: chain}
+	}
+
+	cs := spew.ConfigState{Indent: " ", DisablePointerAddresses: true}
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{cs.Sdump(self), "(*spew_test.selfDumper)(N=1 (*spew_test.selfDumper)(" +
+			"<recursive method call> {\n N: (int) 1,\n" +
+			" Next: (*spew_test.selfDumper)(<already shown>)\n})\n)\n"},
+		{cs.Sdump(list), "(*spew_test.selfDumper)(N=1 (*spew_test.selfDumper)(" +
+			"N=2 (*spew_test.selfDumper)(<nil>)\n)\n)\n"},
+		{spew.Sprint(*chain), "1>2>3>4>5>6>7>8>9>10>11>12"},
+	}
+
+	for i, test := range tests {
+		if test.got != test.want {
+			t.Errorf("Recursive method #%d\n got: %q want: %q", i, test.got,
+				test.want)
+		}
+	}
+}
+
+// TestConcurrentMethods ensures methods invoked while displaying values of the
+// same type on other goroutines are not mistaken for recursive calls.
+func TestConcurrentMethods(t *testing.T) {
+	var wg sync.WaitGroup
+	results := make([]string, 32)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = spew.Sdump(childSprinter{N: i, Child: &childSprinter{N: 1}})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, got := range results {
+		want := fmt.Sprintf("(spew_test.childSprinter) %d>1\n", i)
+		if got != want {
+			t.Errorf("Concurrent method #%d\n got: %q want: %q", i, got, want)
+		}
+	}
+}
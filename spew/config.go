@@ -20,7 +20,23 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"reflect"
+	"strings"
+)
+
+// ComplexFormat identifies the form complex numbers are displayed in.
+type ComplexFormat int
+
+const (
+	// ComplexRectangular displays complex numbers by their real and
+	// imaginary parts, such as (3+4i).  It is the default.
+	ComplexRectangular ComplexFormat = iota
+
+	// ComplexPolar displays complex numbers by their magnitude and phase
+	// angle in radians separated by an angle sign, such as (5∠0.9273).
+	ComplexPolar
 )
 
 // ConfigState houses the configuration options used by spew to format and
@@ -33,7 +49,8 @@ import (
 //
 // Alternatively, you can use NewDefaultConfig to get a ConfigState instance
 // with default settings.  See the documentation of NewDefaultConfig for default
-// values.
+// values.  NewConfig may also be used to get a ConfigState instance with the
+// default settings modified by the passed options.
 type ConfigState struct {
 	// Indent specifies the string to use for each indentation level.  The
 	// global config instance that all top-level functions use set this to a
@@ -41,18 +58,97 @@ type ConfigState struct {
 	// set this to a tab with "\t" or perhaps two spaces with "  ".
 	Indent string
 
+	// IndentWidth specifies the number of spaces to use for each indentation
+	// level when Indent is empty.  Indent takes precedence when both are set
+	// for compatibility, so Indent must be cleared for IndentWidth to take
+	// effect on the global config instance, which WithIndentWidth does.  The
+	// default, 0, means no indentation is performed when Indent is empty.
+	IndentWidth int
+
+	// LinePrefix specifies a string which Dump and its variants write at the
+	// start of every line of output, including the continuation lines of
+	// values which span multiple lines, such as hexdumps.  This is useful for
+	// quoting dumps in Markdown with "> " or nesting them inside other
+	// output.
+	LinePrefix string
+
 	// MaxDepth controls the maximum number of levels to descend into nested
-	// data structures.  The default, 0, means there is no limit.
+	// data structures.  The default, 0, means there is no limit other than a
+	// hard limit of 10000 levels which prevents exhausting the stack.
 	//
 	// NOTE: Circular data structures are properly detected, so it is not
 	// necessary to set this value unless you specifically want to limit deeply
 	// nested data structures.
 	MaxDepth int
 
+	// MaxStringLength specifies the maximum number of runes to display for
+	// string values.  Longer strings are truncated on a rune boundary and
+	// followed by a marker which includes the full length in bytes.  The
+	// default, 0, means there is no limit.
+	MaxStringLength int
+
+	// MaxSliceElements specifies the maximum number of elements to display
+	// for arrays and slices.  Any remaining elements are summarized by a
+	// marker which includes how many were omitted.  The default, 0, means
+	// there is no limit.
+	MaxSliceElements int
+
+	// BytesPerLine specifies the number of bytes displayed on each line of
+	// the hexdumps of byte arrays and slices produced by Dump as well as of
+	// the output of Bytedump.  The default, 0, means 16 bytes are displayed
+	// on each line, the same as the hexdump -C command.
+	BytesPerLine int
+
+	// MaxMapEntries specifies the maximum number of key/value pairs to
+	// display for maps.  Any remaining entries are summarized by a marker
+	// which includes how many were omitted.  Combine this with SortKeys to
+	// make the displayed entries deterministic.  The default, 0, means there
+	// is no limit.
+	MaxMapEntries int
+
+	// MaxStructFields specifies the maximum number of fields to display for
+	// structs.  Any remaining fields are summarized by a marker which
+	// includes how many were omitted.  Unexported fields count toward the
+	// limit.  Combine this with SortFields to choose the displayed fields by
+	// name rather than declaration order.  The default, 0, means there is no
+	// limit.
+	MaxStructFields int
+
+	// MaxPointerChain specifies the maximum number of pointer addresses to
+	// display when indirecting through multiple levels of pointers.  Any
+	// remaining addresses are replaced by a ->... marker, although the
+	// entire chain is still followed in order to detect circular
+	// references.  The default, 0, means there is no limit.
+	MaxPointerChain int
+
+	// MaxOutputBytes specifies the maximum number of bytes Dump and its
+	// variants output for all of the passed arguments combined.  Once it is
+	// reached, the output is cut off, no further values are descended into,
+	// and a final line noting the output was truncated is added.  This
+	// bounds the output of structures which are too wide for the other
+	// limits to help.  The default, 0, means there is no limit.
+	MaxOutputBytes int
+
 	// DisableMethods specifies whether or not error and Stringer interfaces are
 	// invoked for types that implement them.
 	DisableMethods bool
 
+	// DisableMethodsForTypes specifies types for which error and Stringer
+	// interfaces are not invoked even though method invocation is otherwise
+	// enabled.  This is useful for suppressing the methods of a single type
+	// whose methods are slow or recurse endlessly.  Only values of exactly
+	// the listed types are affected, so pointers to them are listed
+	// separately when needed.
+	DisableMethodsForTypes map[reflect.Type]bool
+
+	// OnlyDirectMethods specifies whether or not error and Stringer
+	// interfaces are only invoked when their methods are declared on the type
+	// of the value rather than promoted from one of its embedded fields.
+	// This is useful for displaying the fields of structs which embed a type
+	// that implements one of the interfaces instead of the output of the
+	// method of the embedded type.
+	OnlyDirectMethods bool
+
 	// DisablePointerMethods specifies whether or not to check for and invoke
 	// error and Stringer interfaces on types which only accept a pointer
 	// receiver when the current type is not a pointer.
@@ -67,15 +163,198 @@ type ConfigState struct {
 	// Google App Engine or with the "safe" build tag specified.
 	DisablePointerMethods bool
 
+	// DisableDumperInterface specifies whether or not Dump and its variants
+	// invoke the SpewDump method of types which implement the Dumper
+	// interface.  Like error and Stringer interfaces, it is invoked on types
+	// which only accept a pointer receiver unless DisablePointerMethods is
+	// set.
+	DisableDumperInterface bool
+
+	// UseTextMarshaler specifies whether or not the MarshalText method is
+	// invoked for types that implement the encoding.TextMarshaler interface,
+	// such as net.IP, with the resulting text displayed in place of the
+	// value.  It has lower priority than the error and Stringer interfaces
+	// and, like them, is not invoked when DisableMethods is set.  Values
+	// whose MarshalText method returns an error are displayed as usual.
+	UseTextMarshaler bool
+
+	// UnwrapErrors specifies whether or not the messages of the errors an
+	// error wraps, as returned by an Unwrap() error or Unwrap() []error
+	// method, are displayed after its own message when error interfaces are
+	// invoked.  Each layer of the chain is separated by an arrow, such as
+	// "err -> cause -> root", and errors which wrap multiple errors have the
+	// chain of each one displayed as a bracketed list.  Combine with
+	// ContinueOnMethod to also display the underlying value of the error.
+	UnwrapErrors bool
+
 	// DisablePointerAddresses specifies whether to disable the printing of
 	// pointer addresses. This is useful when diffing data structures in tests.
 	DisablePointerAddresses bool
 
+	// DisablePointerFollowing specifies whether or not Dump and its variants
+	// stop at pointers rather than dereferencing them, so only the type and
+	// address of non-nil pointers are displayed, such as (*main.Big)(0x...).
+	// This is useful for seeing the shape of large graphs of shared data
+	// without displaying everything reachable from them.
+	DisablePointerFollowing bool
+
+	// PointerAliases specifies whether or not Dump and its variants display
+	// sequential ids, such as 0x1 and 0x2, in place of pointer addresses.
+	// Each distinct address is assigned the next id the first time it is
+	// encountered during a call and the same id is displayed every time it is
+	// encountered again, so shared pointers are still recognizable while the
+	// output is identical between runs.  This is useful for golden files.
+	PointerAliases bool
+
+	// NilString specifies the marker to display in place of nil values, such
+	// as nil pointers, slices, and maps.  This is useful when the output is
+	// parsed by other tools or embedded in contexts, such as HTML, where the
+	// angle brackets of the default are inconvenient.  The default, "",
+	// means <nil> is displayed.
+	NilString string
+
+	// CircularString specifies the marker to display in place of circular
+	// references to values which are already being displayed.  The default,
+	// "", means <already shown> is displayed by Dump and its variants and
+	// <shown> is displayed by the Formatter.
+	CircularString string
+
 	// DisableCapacities specifies whether to disable the printing of capacities
-	// for arrays, slices, maps and channels. This is useful when diffing
-	// data structures in tests.
+	// for slices and channels. This is useful when diffing data structures in
+	// tests.
 	DisableCapacities bool
 
+	// DisableLengths specifies whether to disable the printing of lengths for
+	// arrays, slices, maps, channels and strings.  Capacities are still
+	// displayed unless DisableCapacities is also set.
+	DisableLengths bool
+
+	// DisableTimeFormat specifies whether or not to disable displaying
+	// time.Time values as an RFC3339 timestamp when they are not already
+	// handled by their String method, such as when DisableMethods is set.
+	// When disabled, their internal fields are displayed instead.
+	DisableTimeFormat bool
+
+	// DisableSyncMapExpansion specifies whether or not to disable displaying
+	// the entries of sync.Map values like a regular map when dumping.  The
+	// entries are collected via the concurrency safe Range method.  When
+	// disabled, their internal fields are displayed instead.
+	DisableSyncMapExpansion bool
+
+	// DisableAtomicExpansion specifies whether or not to disable displaying
+	// the current value of the types provided by the sync/atomic package,
+	// such as atomic.Int64 and atomic.Value, in place of their internals when
+	// dumping.  The value is obtained via the Load method, so it is read
+	// atomically.  When disabled, their internal fields are displayed
+	// instead, which the race detector may flag.
+	DisableAtomicExpansion bool
+
+	// FloatFormat specifies the format used to display floating point values
+	// and the parts of complex values.  It accepts the same format bytes as
+	// strconv.FormatFloat, such as 'f' for -ddd.dddd and 'e' for -d.dddde±dd,
+	// so the output is always locale-independent.  The JSON output is not
+	// affected.  The default, 0, means 'g'.
+	FloatFormat byte
+
+	// FloatPrecision specifies the number of digits used to display floating
+	// point values and the parts of complex values.  Its meaning depends on
+	// FloatFormat the same as the precision of strconv.FormatFloat.  The
+	// default, 0, as well as any negative value, means the smallest number of
+	// digits necessary to represent the value exactly.  See
+	// ZeroFloatPrecision to display no digits after the decimal point.
+	FloatPrecision int
+
+	// ZeroFloatPrecision specifies that a FloatPrecision of 0 is used as the
+	// precision rather than meaning the default, so floating point values
+	// are displayed with no digits after the decimal point with the 'f'
+	// format.
+	ZeroFloatPrecision bool
+
+	// FloatSpecialAsString specifies whether or not NaN and infinite floating
+	// point values, including the parts of complex values, are displayed as
+	// the Go expressions math.NaN(), math.Inf(1), and math.Inf(-1) instead of
+	// NaN, +Inf, and -Inf.  Complex values with such a part are displayed as
+	// a call to complex.  This is useful along with GoSyntax since the
+	// output is then valid Go.
+	FloatSpecialAsString bool
+
+	// ComplexFormat specifies the form complex numbers are displayed in.
+	// Both of their parts are displayed according to FloatPrecision.  The
+	// default, ComplexRectangular, displays their real and imaginary parts.
+	// Complex numbers are always displayed in rectangular form when GoSyntax
+	// is set.
+	ComplexFormat ComplexFormat
+
+	// IntegerBase specifies the base in which integer values are displayed.
+	// Binary, octal, and hexadecimal values are prefixed with 0b, 0o, and
+	// 0x, respectively.  Lengths, capacities, and the JSON output are always
+	// decimal while pointers and uintptr values are always hexadecimal.  The
+	// default, 0, as well as any base outside of the range 2 through 36,
+	// means base 10.
+	IntegerBase int
+
+	// RuneAsChar specifies whether or not rune values are displayed as a
+	// quoted character followed by their numeric value, such as 'A' (65),
+	// instead of only the number.  Code points which are not printable are
+	// escaped, such as '\u200b'.  Since rune is an alias for int32, the
+	// reflect package can't tell them apart, so this applies to all int32
+	// values, although named types based on int32 are not affected.
+	RuneAsChar bool
+
+	// OmitZero specifies whether or not struct fields and map entries whose
+	// values are the zero value for their type are skipped.  Nil pointers,
+	// interfaces, maps, slices, channels, and funcs, empty strings, zero
+	// numbers, and false are all zero, as are arrays and structs which
+	// consist entirely of zero values.  Note that empty but non-nil maps and
+	// slices are not zero.
+	OmitZero bool
+
+	// OmitFunc specifies a function which is invoked with the path to and
+	// value of each struct field, array or slice element, and map value
+	// before it is displayed by Dump and its variants.  When it returns true,
+	// the field, element, or entry is omitted entirely.  Paths have the same
+	// form as those passed to Redact.  The elements of byte arrays and slices
+	// which are displayed as a hexdump are not passed to it.  The default,
+	// nil, means nothing is omitted.
+	OmitFunc func(path string, v reflect.Value) bool
+
+	// BytesAsString specifies whether or not byte arrays and slices which
+	// consist entirely of printable UTF-8 text are displayed by Dump as a
+	// quoted string instead of a hexdump.  Data that contains invalid UTF-8
+	// or any non-printable characters, including newlines, is still
+	// hexdumped.
+	BytesAsString bool
+
+	// MaxArrayBytes specifies the maximum number of bytes of byte arrays and
+	// slices which are hexdumped by Dump.  Larger ones are summarized by
+	// their length along with their first and last few bytes in hex, such as
+	// <32 bytes: 00112233...ccddeeff>, which keeps fixed-size data such as
+	// hashes and keys from dominating the output.  Those which are displayed
+	// as a string due to BytesAsString are not summarized.  The default, 0,
+	// means there is no limit.
+	MaxArrayBytes int
+
+	// RunesAsString specifies whether or not rune arrays and slices are
+	// displayed by Dump as a quoted string instead of a list of code points.
+	// Non-printable runes are escaped, such as \u00a0.  Those which contain
+	// invalid code points are still displayed as a list.  Since rune is an
+	// alias for int32, this applies to int32 arrays and slices as well.
+	RunesAsString bool
+
+	// RawStrings specifies whether or not Dump and its variants display
+	// strings with only control characters, invalid UTF-8, double quotes,
+	// and backslashes escaped, with the bytes of control characters and
+	// invalid UTF-8 escaped as \xNN.  Any other text, including non-ASCII
+	// characters which strconv.Quote escapes as not printable, is displayed
+	// as is.
+	RawStrings bool
+
+	// DisableStringQuoting specifies whether or not Dump and its variants
+	// display strings verbatim instead of as a quoted string, such as
+	// (string) hello world.  The lines after the first of strings which
+	// contain newlines are indented one level deeper than the string.
+	DisableStringQuoting bool
+
 	// ContinueOnMethod specifies whether or not recursion should continue once
 	// a custom error or Stringer interface is invoked.  The default, false,
 	// means it will print the results of invoking the custom error or Stringer
@@ -86,10 +365,153 @@ type ConfigState struct {
 	// via the DisableMethods or DisablePointerMethods options.
 	ContinueOnMethod bool
 
+	// DedupPointers specifies whether or not the contents of a pointer that
+	// has already been dumped are shown again when the same pointer is
+	// reached through another path.  When enabled, repeated pointers display
+	// their type and address followed by an <already dumped> marker instead
+	// of the full contents.  This can drastically reduce the output size for
+	// object graphs with a lot of sharing at the cost of remembering every
+	// dumped pointer for the duration of the call, which requires memory
+	// proportional to the number of distinct pointers.
+	//
+	// NOTE: Circular references are always detected regardless of this
+	// setting.  This option only affects pointers shared between siblings.
+	DedupPointers bool
+
+	// Compact specifies whether or not Dump and its variants output each
+	// argument on a single line.  The type information and field names of the
+	// usual format are retained, but entries are separated by ", " and there
+	// is no indentation, which makes the output suitable for log statements.
+	// Byte arrays and slices are output as space separated hex bytes instead
+	// of a hexdump.
+	Compact bool
+
+	// EnableColors specifies whether or not the output of Dump and its
+	// variants is colored with ANSI escape sequences according to the
+	// category of each token.  This is useful when dumping to a terminal, but
+	// should be left disabled when the output is written elsewhere.
+	EnableColors bool
+
+	// Colors specifies the escape sequences to use for each category of token
+	// when EnableColors is set.  The default, nil, means DefaultColors is
+	// used.
+	Colors *Colors
+
+	// JSON specifies whether or not Dump and its variants output each
+	// argument as a single line of JSON instead of the usual format.  Structs
+	// and maps become objects, including unexported struct fields, arrays and
+	// slices become arrays, and values that have no JSON equivalent such as
+	// complex numbers and channels become strings.  Circular references are
+	// output as an object of the form {"$ref":"0x..."} with the address of
+	// the pointer which was already being dumped.  MaxDepth, SortKeys,
+	// OmitZero, OmitFunc, Redact, registered formatters, and the method
+	// options are honored, with the output of formatters and methods
+	// becoming strings.  MaxStringLength, MaxSliceElements, MaxMapEntries,
+	// and MaxStructFields have no effect since their truncation markers
+	// would change the shape of the data, and options that only affect the
+	// layout of the usual format are ignored.
+	JSON bool
+
+	// GoSyntax specifies whether or not Dump and its variants output each
+	// argument as a Go expression which can be pasted back into source code,
+	// such as for generating test fixtures.  Structs, arrays, slices, and
+	// maps become composite literals, pointers take the address of their
+	// value, and strings are quoted.  Unexported struct fields are included,
+	// so the output only compiles within the package which defines them.
+	// Values which can't be expressed, such as non-nil channels and
+	// functions as well as circular references, become nil followed by a
+	// comment.  Indent, Compact, MaxDepth, OmitZero, and SortKeys are honored
+	// while methods are never invoked.  JSON takes precedence when both are
+	// set.  The Formatter also outputs the same expression on a single line
+	// for the %#v and %#+v verbs when it is set.
+	GoSyntax bool
+
+	// Redact specifies a function which is invoked with the path to and value
+	// of each struct field, array or slice element, and map value, as well as
+	// each top-level argument, before it is displayed.  When it returns true,
+	// the returned replacement string is displayed instead of the value,
+	// which is useful for masking sensitive data such as passwords before it
+	// reaches logs.  Paths are of the form .Field[idx]["key"] where map keys
+	// are formatted the same as the Formatter would display them and the path
+	// to a top-level argument is empty.  The default, nil, means nothing is
+	// redacted.
+	Redact func(path string, v reflect.Value) (string, bool)
+
+	// SortFields specifies whether or not struct fields are displayed sorted
+	// by name instead of in declaration order.  This reduces noise when
+	// comparing output across versions of code where the field order
+	// changed.  Embedded fields are named after their type, so they sort by
+	// their type name.
+	SortFields bool
+
+	// ExportedOnly specifies whether or not only the exported fields of
+	// structs are displayed.  Unexported fields, including embedded fields
+	// of unexported types, are skipped entirely, so the unsafe package is
+	// never used to access them.  This provides a public view of values
+	// which is suitable for output that might be shared externally.
+	ExportedOnly bool
+
+	// ShowFieldTags specifies whether or not Dump and its variants display
+	// the raw tag of each struct field, quoted in backquotes the same as in
+	// the source, after the name of the field.  Fields without a tag are
+	// displayed as usual.
+	ShowFieldTags bool
+
+	// MarkEmbedded specifies whether or not Dump and its variants display an
+	// <embedded> marker before the name of embedded struct fields, which are
+	// named after their type, to distinguish them from regular fields.
+	MarkEmbedded bool
+
+	// ShortTypeNames specifies whether or not displayed type names have the
+	// directories of package paths removed so only package names remain,
+	// such as pkg.Thing instead of github.com/org/proj/internal/pkg.Thing.
+	// Qualified paths are only part of the names of some types, such as
+	// those of generic types instantiated with types from other packages.
+	ShortTypeNames bool
+
+	// ShowInterfaceType specifies whether or not Dump and its variants
+	// display the type of the interface a value is stored in, such as a
+	// struct field, slice element, or map entry of an interface type, before
+	// the concrete type of the value in the form (interface {} = pkg.Type).
+	// This is useful for debugging type assertions.  The type of the
+	// interface is always displayed for interfaces which hold a nil pointer,
+	// map, slice, channel, or function since the interface itself is not nil.
+	ShowInterfaceType bool
+
+	// ShowKind specifies whether or not Dump and its variants display the
+	// reflect.Kind of a value after its type, such as
+	// (main.Celsius kind=float64).  The kind displayed for pointers is that
+	// of the type they ultimately point to.  This is useful for named types
+	// whose underlying kind isn't obvious from their name.
+	ShowKind bool
+
+	// ShowCollectionCounts specifies whether or not Dump and its variants
+	// display the total number of entries of arrays, slices, and maps after
+	// their type, such as (map[string]int) (count=10432).  The count is
+	// displayed even when MaxMapEntries or MaxSliceElements limit the
+	// entries which are shown.
+	ShowCollectionCounts bool
+
+	// ShowHeaderAddresses specifies whether or not Dump and its variants
+	// display the address of the data referenced by non-nil maps, slices,
+	// and channels after their type, such as (map[string]int)(0xc000010000),
+	// which shows whether they share the same underlying data.  It has no
+	// effect when DisablePointerAddresses is set.
+	ShowHeaderAddresses bool
+
+	// TypedLiterals specifies whether or not Dump and its variants display
+	// numbers as conversions to their type, such as uint8(255) and
+	// float32(3.14), so they can be pasted into Go source with the same
+	// type.  The type information that is normally displayed before a
+	// number is omitted since it would be redundant.
+	TypedLiterals bool
+
 	// SortKeys specifies map keys should be sorted before being printed. Use
 	// this to have a more deterministic, diffable output.  Note that only
-	// native types (bool, int, uint, floats, uintptr and string) and types
-	// that support the error or Stringer interfaces (if methods are
+	// native types (bool, int, uint, floats, complexes, uintptr and string),
+	// pointers, which are sorted by the values they point to, arrays and
+	// structs, which are sorted element by element and field by field, and
+	// types that support the error or Stringer interfaces (if methods are
 	// enabled) are supported, with other types sorted according to the
 	// reflect.Value.String() output which guarantees display stability.
 	SortKeys bool
@@ -98,12 +520,40 @@ type ConfigState struct {
 	// be spewed to strings and sorted by those strings.  This is only
 	// considered if SortKeys is true.
 	SpewKeys bool
+
+	// formatters houses the functions registered by RegisterFormatter to
+	// display values of specific types.
+	formatters map[reflect.Type]FormatterFunc
+
+	// methods is the set of values with a method in progress for the dump
+	// which owns the config state.  It is shared by the snapshots of the
+	// config state passed to Dumper implementations so dumps they start can
+	// detect recursion.
+	methods map[methodCall]bool
 }
 
 // Config is the active configuration of the top-level functions.
 // The configuration can be changed by modifying the contents of spew.Config.
+//
+// Each top-level function works from a snapshot of Config taken when it is
+// called, so changes do not affect dumps which are already in progress.
+// However, Config is not protected by a lock, so it should only be modified
+// during initialization, before it is used concurrently.  Use a separate
+// ConfigState for settings which need to vary while the program is running.
 var Config = ConfigState{Indent: " "}
 
+// snapshot returns a copy of the config state.  Operations work from a
+// snapshot taken when they start so their output is consistent even when the
+// config state is modified while they are running.  The indentation for the
+// IndentWidth option is computed once for the snapshot.
+func (c *ConfigState) snapshot() *ConfigState {
+	cs := *c
+	if cs.Indent == "" && cs.IndentWidth > 0 {
+		cs.Indent = strings.Repeat(" ", cs.IndentWidth)
+	}
+	return &cs
+}
+
 // Errorf is a wrapper for fmt.Errorf that treats each argument as if it were
 // passed with a Formatter interface returned by c.NewFormatter.  It returns
 // the formatted string as a value that satisfies error.  See NewFormatter
@@ -238,13 +688,14 @@ use of the custom formatter by calling one of the convenience functions such as
 c.Printf, c.Println, or c.Printf.
 */
 func (c *ConfigState) NewFormatter(v interface{}) fmt.Formatter {
-	return newFormatter(c, v)
+	return newFormatter(c.snapshot(), v)
 }
 
 // Fdump formats and displays the passed arguments to io.Writer w.  It formats
-// exactly the same as Dump.
-func (c *ConfigState) Fdump(w io.Writer, a ...interface{}) {
-	fdump(c, w, a...)
+// exactly the same as Dump.  It returns the number of bytes written and the
+// first write error encountered, after which nothing more is written.
+func (c *ConfigState) Fdump(w io.Writer, a ...interface{}) (n int, err error) {
+	return fdump(c, w, a...)
 }
 
 /*
@@ -282,13 +733,185 @@ func (c *ConfigState) Sdump(a ...interface{}) string {
 	return buf.String()
 }
 
+// FdumpAll formats and displays the passed arguments to each of the passed
+// writers exactly the same as Fdump.  See the top-level FdumpAll function for
+// details.
+func (c *ConfigState) FdumpAll(writers []io.Writer, a ...interface{}) error {
+	return fdumpAll(c, writers, a...)
+}
+
+// Dumpb returns a byte slice with the passed arguments formatted exactly the
+// same as Dump.
+func (c *ConfigState) Dumpb(a ...interface{}) []byte {
+	var buf bytes.Buffer
+	fdump(c, &buf, a...)
+	return buf.Bytes()
+}
+
+// DumpSize returns the number of bytes Dump would output for the passed
+// arguments according to the config state without keeping the output.  See
+// the top-level DumpSize function for details.
+func (c *ConfigState) DumpSize(a ...interface{}) int {
+	n, _ := fdump(c, ioutil.Discard, a...)
+	return n
+}
+
+// Fbytedump formats and displays the passed data to io.Writer w in the classic
+// offset, hex, and ASCII layout according to the config state.  See the
+// top-level Fbytedump function for details.
+func (c *ConfigState) Fbytedump(w io.Writer, data []byte) (n int, err error) {
+	return fbytedump(c, w, data)
+}
+
+// Bytedump returns a string with the passed data formatted exactly the same as
+// Fbytedump according to the config state.
+func (c *ConfigState) Bytedump(data []byte) string {
+	var buf bytes.Buffer
+	fbytedump(c, &buf, data)
+	return buf.String()
+}
+
+// Gdump outputs the graph of the passed value to io.Writer w in the GraphViz
+// DOT language according to the config state.  See the top-level Gdump
+// function for details.
+func (c *ConfigState) Gdump(w io.Writer, v interface{}) (n int, err error) {
+	return fgdump(c, w, v)
+}
+
+// Diff walks the passed values in lockstep and returns the differences between
+// them, or an empty string when there are none.  See the top-level Diff
+// function for details.
+func (c *ConfigState) Diff(a, b interface{}) string {
+	return fdiff(c, a, b)
+}
+
+// Query returns the value located at the passed path within the passed value
+// according to the config state.  See the top-level Query function for
+// details.
+func (c *ConfigState) Query(v interface{}, path string) (interface{}, bool) {
+	return fquery(c, v, path)
+}
+
+// Walk traverses the passed value and invokes fn with each value it visits
+// along with the path to it according to the config state.  See the top-level
+// Walk function for details.
+func (c *ConfigState) Walk(v interface{}, fn func(path string, val reflect.Value) bool) {
+	fwalk(c, v, fn)
+}
+
+// Tokens traverses the passed value and invokes emit with a stream of tokens
+// which describe it according to the config state.  See the top-level Tokens
+// function for details.
+func (c *ConfigState) Tokens(v interface{}, emit func(Token)) {
+	ftokens(c, v, emit)
+}
+
+// SortValues sorts the passed values in place into the same order the SortKeys
+// option uses for map keys according to the config state.  See the top-level
+// SortValues function for details.
+func (c *ConfigState) SortValues(values []reflect.Value) {
+	sortValues(values, c)
+}
+
+// hardMaxDepth is the maximum number of levels nested data structures are
+// descended into regardless of the MaxDepth option.  It prevents exhausting the
+// goroutine stack, which crashes the program, on pathologically deep data
+// structures such as very long linked lists.
+const hardMaxDepth = 10000
+
+// depthExceeded returns whether or not the passed depth is beyond the depth
+// allowed by the MaxDepth option or the hard limit.
+func (c *ConfigState) depthExceeded(depth int) bool {
+	return depth > hardMaxDepth || (c.MaxDepth != 0 && depth > c.MaxDepth)
+}
+
+// intBase returns the base to use when displaying integer values according to
+// the IntegerBase option.
+func (c *ConfigState) intBase() int {
+	if c.IntegerBase < 2 || c.IntegerBase > 36 {
+		return 10
+	}
+	return c.IntegerBase
+}
+
+// typeName returns the name to display for the passed type according to the
+// ShortTypeNames option.
+func (c *ConfigState) typeName(t reflect.Type) string {
+	if c.ShortTypeNames {
+		return shortTypeName(t.String())
+	}
+	return t.String()
+}
+
+// bytesPerLine returns the number of bytes to display on each line of a
+// hexdump according to the BytesPerLine option.
+func (c *ConfigState) bytesPerLine() int {
+	if c.BytesPerLine <= 0 {
+		return 16
+	}
+	return c.BytesPerLine
+}
+
+// nilMarker returns the marker to display for nil values according to the
+// NilString option.
+func (c *ConfigState) nilMarker() []byte {
+	if c.NilString == "" {
+		return nilAngleBytes
+	}
+	return []byte(c.NilString)
+}
+
+// circularMarker returns the marker to display for circular references
+// according to the CircularString option, or the passed default marker for
+// the output style when it is not set.
+func (c *ConfigState) circularMarker(def []byte) []byte {
+	if c.CircularString == "" {
+		return def
+	}
+	return []byte(c.CircularString)
+}
+
+// floatSpecials returns whether or not NaN and infinite floating point values
+// are displayed as Go expressions according to the FloatSpecialAsString
+// option.  A nil config state uses the default.
+func (c *ConfigState) floatSpecials() bool {
+	return c != nil && c.FloatSpecialAsString
+}
+
+// polarComplex returns whether or not complex numbers are displayed in polar
+// form according to the ComplexFormat option.  A nil config state uses the
+// default.
+func (c *ConfigState) polarComplex() bool {
+	return c != nil && c.ComplexFormat == ComplexPolar
+}
+
+// floatFormat returns the format byte and precision to use with
+// strconv.FormatFloat when displaying floating point values according to the
+// FloatFormat and FloatPrecision options.  A nil config state uses the
+// defaults.
+func (c *ConfigState) floatFormat() (byte, int) {
+	format, digits := byte('g'), -1
+	if c == nil {
+		return format, digits
+	}
+	switch c.FloatFormat {
+	case 'b', 'e', 'E', 'f', 'g', 'G', 'x', 'X':
+		format = c.FloatFormat
+	}
+	if c.FloatPrecision > 0 || (c.FloatPrecision == 0 && c.ZeroFloatPrecision) {
+		digits = c.FloatPrecision
+	}
+	return format, digits
+}
+
 // convertArgs accepts a slice of arguments and returns a slice of the same
 // length with each argument converted to a spew Formatter interface using
 // the ConfigState associated with s.
 func (c *ConfigState) convertArgs(args []interface{}) (formatters []interface{}) {
+	cs := c.snapshot()
 	formatters = make([]interface{}, len(args))
 	for index, arg := range args {
-		formatters[index] = newFormatter(c, arg)
+		formatters[index] = newFormatter(cs, arg)
 	}
 	return formatters
 }
@@ -304,3 +927,55 @@ func (c *ConfigState) convertArgs(args []interface{}) (formatters []interface{})
 func NewDefaultConfig() *ConfigState {
 	return &ConfigState{Indent: " "}
 }
+
+// Reset restores the config state to the default settings described by
+// NewDefaultConfig without allocating a new instance, which allows a pooled
+// config state to be reused.  It must not be called while the config state is
+// in use by another goroutine.
+func (c *ConfigState) Reset() {
+	*c = ConfigState{Indent: " "}
+}
+
+// Clone returns a copy of the config state which can be modified without
+// affecting the original, including the formatters registered with
+// RegisterFormatter and the DisableMethodsForTypes map.
+func (c *ConfigState) Clone() *ConfigState {
+	cs := *c
+	cs.methods = nil
+	if c.formatters != nil {
+		cs.formatters = make(map[reflect.Type]FormatterFunc, len(c.formatters))
+		for t, fn := range c.formatters {
+			cs.formatters[t] = fn
+		}
+	}
+	if c.DisableMethodsForTypes != nil {
+		cs.DisableMethodsForTypes = make(map[reflect.Type]bool,
+			len(c.DisableMethodsForTypes))
+		for t, disabled := range c.DisableMethodsForTypes {
+			cs.DisableMethodsForTypes[t] = disabled
+		}
+	}
+	return &cs
+}
+
+// RegisterFormatter registers the passed function to display values of type t
+// with Dump and its variants in place of the Dumper interface, error and
+// Stringer methods, and the value's contents.  When t is a pointer type, it is
+// also used for addressable values of the type t points to, such as those
+// reached through a pointer, and is passed a pointer to them.  Registering
+// another function for the same type replaces the existing one.
+//
+// Like the other settings, formatters should be registered before the config
+// state is used concurrently.
+func (c *ConfigState) RegisterFormatter(t reflect.Type, fn FormatterFunc) {
+	if c.formatters == nil {
+		c.formatters = make(map[reflect.Type]FormatterFunc)
+	}
+	c.formatters[t] = fn
+}
+
+// UnregisterFormatter removes the function registered by RegisterFormatter for
+// type t, if any.
+func (c *ConfigState) UnregisterFormatter(t reflect.Type) {
+	delete(c.formatters, t)
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// Some constants in the form of bytes used when producing a diff.
+var (
+	diffRemovedBytes = []byte("- ")
+	diffAddedBytes   = []byte("+ ")
+	rootPathBytes    = []byte(".")
+)
+
+// diffState contains information about the state of a diff operation.
+type diffState struct {
+	buf     bytes.Buffer
+	visited map[[2]uintptr]bool
+	refs    map[[2]uintptr]bool
+	depth   int
+	cs      *ConfigState
+	leafCS  ConfigState
+}
+
+// unpackInterface returns the value inside of a non-nil interface.
+func unpackInterface(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// writeLeaf writes a single line of the diff for the value at the passed path
+// using prefix to indicate whether it was removed or added.  The value is
+// rendered the same as a compact Dump.
+func (ds *diffState) writeLeaf(prefix []byte, path string, v reflect.Value) {
+	ds.buf.Write(prefix)
+	if path == "" {
+		ds.buf.Write(rootPathBytes)
+	} else {
+		ds.buf.WriteString(path)
+	}
+	ds.buf.Write(colonSpaceBytes)
+	if !v.IsValid() {
+		ds.buf.Write(invalidAngleBytes)
+	} else {
+		d := dumpState{w: &ds.buf, cs: &ds.leafCS}
+		d.pointers = make(map[uintptr]int)
+		d.dumpPath(path, v)
+	}
+	ds.buf.Write(newlineBytes)
+}
+
+// changed writes the lines of the diff which show the value at the passed path
+// changed from a to b.
+func (ds *diffState) changed(path string, a, b reflect.Value) {
+	ds.writeLeaf(diffRemovedBytes, path, a)
+	ds.writeLeaf(diffAddedBytes, path, b)
+}
+
+// leafEqual returns whether or not the passed values, which must be of the same
+// type, are equal.  Values which can't be descended into, such as channels and
+// funcs, are considered equal when they refer to the same thing.
+func leafEqual(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return a.Int() == b.Int()
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uint, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+
+	case reflect.String:
+		return a.String() == b.String()
+
+	case reflect.UnsafePointer, reflect.Chan, reflect.Func:
+		return a.Pointer() == b.Pointer()
+
+	case reflect.Interface:
+		// The only time we should get here is for nil interfaces due to
+		// unpackInterface calls.
+		return a.IsNil() && b.IsNil()
+	}
+	return true
+}
+
+// enterRefs marks the data referenced by the passed slices or maps as being
+// compared and returns whether or not they need to be.  They don't when they
+// are already being compared further up, which means they contain themselves,
+// or when they reference the same data.  Call leaveRefs with the same values
+// once they have been compared.
+func (ds *diffState) enterRefs(a, b reflect.Value) bool {
+	key := [2]uintptr{refAddr(a), refAddr(b)}
+	if key[0] == 0 || key[1] == 0 {
+		return true
+	}
+	if key[0] == key[1] && a.Len() == b.Len() {
+		return false
+	}
+	if ds.refs[key] {
+		return false
+	}
+	ds.refs[key] = true
+	return true
+}
+
+// leaveRefs marks the data referenced by the passed slices or maps as no
+// longer being compared.
+func (ds *diffState) leaveRefs(a, b reflect.Value) {
+	delete(ds.refs, [2]uintptr{refAddr(a), refAddr(b)})
+}
+
+// diff walks the passed values in lockstep and writes the differences between
+// them.  It is a recursive function, however circular data structures are
+// detected and handled properly.
+func (ds *diffState) diff(path string, a, b reflect.Value) {
+	a, b = unpackInterface(a), unpackInterface(b)
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			ds.changed(path, a, b)
+		}
+		return
+	}
+	if a.Type() != b.Type() {
+		ds.changed(path, a, b)
+		return
+	}
+
+	// Stop descending once the maximum depth is exceeded so pathologically
+	// deep values can't exhaust the stack.
+	switch a.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		ds.depth++
+		defer func() { ds.depth-- }()
+		if ds.cs.depthExceeded(ds.depth) {
+			return
+		}
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				ds.changed(path, a, b)
+			}
+			return
+		}
+		if a.Pointer() == b.Pointer() {
+			return
+		}
+		key := [2]uintptr{a.Pointer(), b.Pointer()}
+		if ds.visited[key] {
+			return
+		}
+		ds.visited[key] = true
+		ds.diff(path, a.Elem(), b.Elem())
+
+	case reflect.Struct:
+		vt := a.Type()
+		for _, i := range visibleFields(ds.cs, vt) {
+			ds.diff(fieldPath(path, fieldName(vt.Field(i))), a.Field(i),
+				b.Field(i))
+		}
+
+	case reflect.Slice:
+		if a.IsNil() != b.IsNil() {
+			ds.changed(path, a, b)
+			return
+		}
+		if !ds.enterRefs(a, b) {
+			return
+		}
+		defer ds.leaveRefs(a, b)
+		fallthrough
+
+	case reflect.Array:
+		// Compare the common elements by index and then show any extra
+		// elements as removed or added.
+		numA, numB := a.Len(), b.Len()
+		for i := 0; i < numA && i < numB; i++ {
+			ds.diff(indexPath(path, i), a.Index(i), b.Index(i))
+		}
+		for i := numB; i < numA; i++ {
+			ds.writeLeaf(diffRemovedBytes, indexPath(path, i), a.Index(i))
+		}
+		for i := numA; i < numB; i++ {
+			ds.writeLeaf(diffAddedBytes, indexPath(path, i), b.Index(i))
+		}
+
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() {
+			ds.changed(path, a, b)
+			return
+		}
+		if !ds.enterRefs(a, b) {
+			return
+		}
+		defer ds.leaveRefs(a, b)
+
+		// Compare the entries for keys in both maps and show the entries for
+		// keys in only one of them as removed or added.  The keys are sorted
+		// so the output is deterministic.
+		keys := a.MapKeys()
+		for _, key := range b.MapKeys() {
+			if !a.MapIndex(key).IsValid() {
+				keys = append(keys, key)
+			}
+		}
+		sortValues(keys, ds.cs)
+		for _, key := range keys {
+			va, vb := a.MapIndex(key), b.MapIndex(key)
+			kp := keyPath(ds.cs, path, key)
+			switch {
+			case !vb.IsValid():
+				ds.writeLeaf(diffRemovedBytes, kp, va)
+			case !va.IsValid():
+				ds.writeLeaf(diffAddedBytes, kp, vb)
+			default:
+				ds.diff(kp, va, vb)
+			}
+		}
+
+	default:
+		if !leafEqual(a, b) {
+			ds.changed(path, a, b)
+		}
+	}
+}
+
+// fdiff is a helper function to consolidate the logic from the various public
+// diff methods which take varying config states.
+func fdiff(cs *ConfigState, a, b interface{}) string {
+	cs = cs.snapshot()
+	ds := diffState{cs: cs, leafCS: *cs}
+	ds.visited = make(map[[2]uintptr]bool)
+	ds.refs = make(map[[2]uintptr]bool)
+
+	// Leaves are rendered as a compact dump.
+	ds.leafCS.Compact = true
+	ds.leafCS.JSON = false
+	ds.leafCS.EnableColors = false
+
+	ds.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+	return ds.buf.String()
+}
+
+// Diff walks the passed values in lockstep and returns the differences between
+// them, or an empty string when there are none.  Each difference is output as
+// a line for the old value prefixed with "- " followed by a line for the new
+// value prefixed with "+ ", both keyed by the path to the value such as
+// .Items[2].Name or .Labels["env"].  Structs are compared field by field,
+// arrays and slices by index, and maps by sorted key.  Elements and entries
+// which are only present in one of the values are output with the
+// appropriate prefix alone.  Values are displayed the same as a compact Dump.
+// Values nested deeper than the MaxDepth option are not compared.
+func Diff(a, b interface{}) string {
+	return fdiff(&Config, a, b)
+}
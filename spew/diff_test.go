@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// diffItem and diffOrder are used to test diffing nested data structures.
+type diffItem struct {
+	Name  string
+	Count int
+}
+
+type diffOrder struct {
+	ID     int
+	Items  []diffItem
+	Labels map[string]string
+	Next   *diffOrder
+	note   string
+}
+
+// diffTest is used to describe a test to be performed against Diff.
+type diffTest struct {
+	a, b interface{}
+	want string
+}
+
+// TestDiff executes all of the tests described by diffTests.
+func TestDiff(t *testing.T) {
+	o1 := diffOrder{
+		ID:     1,
+		Items:  []diffItem{{"a", 1}, {"b", 2}, {"old", 3}},
+		Labels: map[string]string{"env": "dev", "team": "x"},
+		note:   "n",
+	}
+	o2 := diffOrder{
+		ID:     1,
+		Items:  []diffItem{{"a", 1}, {"b", 5}, {"new", 3}, {"d", 4}},
+		Labels: map[string]string{"env": "prod", "owner": "y"},
+		note:   "m",
+	}
+	c1 := &diffOrder{ID: 1}
+	c1.Next = c1
+	c2 := &diffOrder{ID: 2}
+	c2.Next = c2
+	s1 := []interface{}{1, nil}
+	s1[1] = s1
+	s2 := []interface{}{2, nil}
+	s2[1] = s2
+	m1 := map[string]interface{}{"n": 1}
+	m1["self"] = m1
+	m2 := map[string]interface{}{"n": 2}
+	m2["self"] = m2
+
+	tests := []diffTest{
+		{1, 1, ""},
+		{1, 2, "- .: (int)1\n+ .: (int)2\n"},
+		{1, "1", "- .: (int)1\n+ .: (string)(len=1)\"1\"\n"},
+		{nil, nil, ""},
+		{nil, 1, "- .: <invalid>\n+ .: (int)1\n"},
+		{o1, o1, ""},
+		{o1, o2, "" +
+			"- .Items[1].Count: (int)2\n" +
+			"+ .Items[1].Count: (int)5\n" +
+			"- .Items[2].Name: (string)(len=3)\"old\"\n" +
+			"+ .Items[2].Name: (string)(len=3)\"new\"\n" +
+			"+ .Items[3]: (spew_test.diffItem){Name:(string)(len=1)\"d\", Count:(int)4}\n" +
+			"- .Labels[\"env\"]: (string)(len=3)\"dev\"\n" +
+			"+ .Labels[\"env\"]: (string)(len=4)\"prod\"\n" +
+			"+ .Labels[\"owner\"]: (string)(len=1)\"y\"\n" +
+			"- .Labels[\"team\"]: (string)(len=1)\"x\"\n" +
+			"- .note: (string)(len=1)\"n\"\n" +
+			"+ .note: (string)(len=1)\"m\"\n"},
+		{map[int]bool{1: true}, map[int]bool{1: false},
+			"- [1]: (bool)true\n+ [1]: (bool)false\n"},
+		{[]int(nil), []int{}, "- .: ([]int)<nil>\n+ .: ([]int){}\n"},
+		{c1, c2, "- .ID: (int)1\n+ .ID: (int)2\n"},
+		{s1, s1, ""},
+		{s1, s2, "- [0]: (int)1\n+ [0]: (int)2\n"},
+		{m1, m2, "- [\"n\"]: (int)1\n+ [\"n\"]: (int)2\n"},
+	}
+
+	for i, test := range tests {
+		got := spew.Diff(test.a, test.b)
+		if got != test.want {
+			t.Errorf("Diff #%d\n got: %q\nwant: %q", i, got, test.want)
+		}
+	}
+}
+
+// TestDiffMaxDepth ensures values nested deeper than the MaxDepth option or
+// the hard limit are not compared.
+func TestDiffMaxDepth(t *testing.T) {
+	a := &diffOrder{ID: 1, Next: &diffOrder{ID: 1}}
+	b := &diffOrder{ID: 2, Next: &diffOrder{ID: 3}}
+	cs := spew.ConfigState{MaxDepth: 1}
+	want := "- .ID: (int)1\n+ .ID: (int)2\n"
+	if got := cs.Diff(a, b); got != want {
+		t.Errorf("Diff MaxDepth\n got: %q\nwant: %q", got, want)
+	}
+
+	// The lists only differ in their last node, which is beyond the hard
+	// limit.
+	la, lb := &diffOrder{ID: 1}, &diffOrder{ID: 2}
+	for i := 0; i < 12000; i++ {
+		la = &diffOrder{Next: la}
+		lb = &diffOrder{Next: lb}
+	}
+	if got := spew.Diff(la, lb); got != "" {
+		t.Errorf("Diff of deep lists got %d bytes", len(got))
+	}
+}
@@ -31,6 +31,11 @@ printing facilities for Go data types are as follows:
 	* Byte arrays and slices are dumped like the hexdump -C command which
 	  includes offsets, byte values in hex, and ASCII output (only when using
 	  Dump style)
+	* Values of types whose internal fields are never useful, such as
+	  time.Time and the math/big numbers, are displayed in a readable form
+	  even when Stringer/error interfaces are not invoked
+	* A reflect.Value is dumped as the value it represents rather than
+	  its internals (only when using Dump style)
 
 There are two different approaches spew allows for dumping Go data structures:
 
@@ -54,6 +59,14 @@ information use Dump, Fdump, or Sdump:
 	spew.Fdump(someWriter, myVar1, myVar2, ...)
 	str := spew.Sdump(myVar1, myVar2, ...)
 
+To limit how deep a single dump descends into nested data structures without
+changing the global configuration, use FdumpMaxDepth or SdumpMaxDepth:
+	str := spew.SdumpMaxDepth(2, myVar1, myVar2, ...)
+
+To dump to a writer according to a specific configuration in a single call, use
+DumpTo:
+	spew.DumpTo(someWriter, &spew.ConfigState{Indent: "\t"}, myVar1, ...)
+
 Alternatively, if you would prefer to use format strings with a compacted inline
 printing style, use the convenience wrappers Printf, Fprintf, etc with
 %v (most compact), %+v (adds pointer addresses), %#v (adds types), or
@@ -63,6 +76,43 @@ printing style, use the convenience wrappers Printf, Fprintf, etc with
 	spew.Fprintf(someWriter, "myVar1: %v -- myVar2: %+v", myVar1, myVar2)
 	spew.Fprintf(someWriter, "myVar3: %#v -- myVar4: %#+v", myVar3, myVar4)
 
+To see how two values differ, such as the expected and actual values in a
+test, use Diff which outputs each difference keyed by its path:
+	str := spew.Diff(want, got)
+
+To inspect raw bytes in the offset, hex, and ASCII layout of the hexdump -C
+command, use Bytedump or Fbytedump:
+	str := spew.Bytedump(data)
+
+To visualize data structures with shared and circular references, use Gdump
+which outputs their graph in the GraphViz DOT language:
+	spew.Gdump(someWriter, myVar)
+
+To build custom tooling on the same traversal, use Walk which invokes a
+callback with each value it visits along with the path to it:
+	spew.Walk(myVar, func(path string, v reflect.Value) bool {
+		return true
+	})
+
+To retrieve a single nested value by the same paths, including values in
+unexported struct fields, use Query:
+	val, ok := spew.Query(myVar, `.Items[2].Labels["env"]`)
+
+Custom renderers can instead use Tokens which describes the value as a stream
+of tokens such as TokenBeginStruct, TokenFieldName, TokenScalar, and TokenEnd:
+	spew.Tokens(myVar, func(tok spew.Token) {
+		fmt.Println(tok.Kind, tok.Text)
+	})
+
+Such renderers can order map keys the same way the SortKeys option does with
+SortValues:
+	keys := reflect.ValueOf(myMap).MapKeys()
+	spew.SortValues(keys)
+
+To log a struct with log/slog as a group of attributes for its fields, which
+requires Go 1.21 or later, use LogValue:
+	slog.Info("state", "obj", spew.LogValue(myVar))
+
 Configuration Options
 
 Configuration of spew is handled by fields in the ConfigState type.  For
@@ -71,44 +121,324 @@ via the spew.Config global.
 
 It is also possible to create a ConfigState instance that provides methods
 equivalent to the top-level functions.  This allows concurrent configuration
-options.  See the ConfigState documentation for more details.
+options.  See the ConfigState documentation for more details.  NewConfig
+creates such an instance from a list of options, each of which sets one of
+the fields below:
+
+	scs := spew.NewConfig(spew.WithIndent("\t"), spew.WithSortKeys())
 
 The following configuration options are available:
 	* Indent
 		String to use for each indentation level for Dump functions.
 		It is a single space by default.  A popular alternative is "\t".
 
+	* IndentWidth
+		Number of spaces to use for each indentation level when Indent is
+		empty.  Indent takes precedence when both are set.
+
+	* LinePrefix
+		String to write at the start of every line of output for Dump
+		functions, such as "> " to quote it in Markdown.  There is no prefix
+		by default.
+
 	* MaxDepth
 		Maximum number of levels to descend into nested data structures.
-		There is no limit by default.
+		There is no limit by default other than a hard limit of 10000
+		levels which prevents exhausting the stack.
+
+	* MaxStringLength
+		Maximum number of runes to display for string values before they
+		are truncated.  There is no limit by default.
+
+	* MaxSliceElements
+		Maximum number of elements to display for arrays and slices before
+		the remainder are summarized.  There is no limit by default.
+
+	* BytesPerLine
+		Number of bytes to display on each line of hexdumps of byte arrays
+		and slices as well as of Bytedump output.  It is 16 by default.
+
+	* MaxMapEntries
+		Maximum number of key/value pairs to display for maps before the
+		remainder are summarized.  Combine with SortKeys for deterministic
+		output.  There is no limit by default.
+
+	* MaxStructFields
+		Maximum number of fields to display for structs before the
+		remainder are summarized.  There is no limit by default.
+
+	* MaxPointerChain
+		Maximum number of pointer addresses to display when indirecting
+		through multiple levels of pointers.  There is no limit by default.
+
+	* MaxOutputBytes
+		Maximum number of bytes to output for all of the passed arguments
+		combined when using Dump style before the remainder is cut off and
+		replaced by a marker.  There is no limit by default.
 
 	* DisableMethods
 		Disables invocation of error and Stringer interface methods.
 		Method invocation is enabled by default.
 
+	* DisableMethodsForTypes
+		Disables invocation of error and Stringer interface methods for
+		the types in the map while leaving it enabled for all others.  No
+		types are excluded by default.
+
+	* OnlyDirectMethods
+		Disables invocation of error and Stringer interface methods which
+		are promoted from embedded fields rather than declared on the type
+		of the value.  Promoted methods are invoked by default.
+
 	* DisablePointerMethods
 		Disables invocation of error and Stringer interface methods on types
 		which only accept pointer receivers from non-pointer variables.
 		Pointer method invocation is enabled by default.
 
+	* DisableDumperInterface
+		Disables invocation of the SpewDump method of types which implement
+		the Dumper interface by Dump style output.  It is invoked by default.
+
+	* UseTextMarshaler
+		Enables invocation of the MarshalText method of types which implement
+		encoding.TextMarshaler but not the error or Stringer interfaces.
+		It is not invoked by default.
+
+	* UnwrapErrors
+		Enables displaying the chain of errors an error wraps via Unwrap
+		methods after its own message, such as "err -> cause -> root".
+		Only the outermost message is displayed by default.
+
 	* DisablePointerAddresses
 		DisablePointerAddresses specifies whether to disable the printing of
 		pointer addresses. This is useful when diffing data structures in tests.
 
+	* DisablePointerFollowing
+		Specifies that Dump style output should only display the type and
+		address of pointers rather than dereferencing them.  Pointers are
+		followed by default.
+
+	* PointerAliases
+		Specifies that Dump style output should display sequential ids in
+		place of pointer addresses, with the same id each time a given
+		address is encountered.  This is useful for golden files.  Actual
+		addresses are displayed by default.
+
+	* NilString
+		String to display in place of nil values.  It is <nil> by default.
+
+	* CircularString
+		String to display in place of circular references.  It is
+		<already shown> for Dump style and <shown> for Formatter style by
+		default.
+
 	* DisableCapacities
 		DisableCapacities specifies whether to disable the printing of
-		capacities for arrays, slices, maps and channels. This is useful when
-		diffing data structures in tests.
+		capacities for slices and channels. This is useful when diffing data
+		structures in tests.
+
+	* DisableLengths
+		Disables displaying the lengths of arrays, slices, maps, channels
+		and strings.  Lengths are displayed by default.
+
+	* DisableTimeFormat
+		Disables displaying time.Time values as an RFC3339 timestamp when
+		methods are not invoked.  Timestamps are displayed by default.
+
+	* DisableSyncMapExpansion
+		Disables displaying the entries of sync.Map values like a regular
+		map when using Dump style.  Entries are displayed by default.
+
+	* DisableAtomicExpansion
+		Disables displaying the current value of sync/atomic types, such as
+		atomic.Int64, in place of their internals when using Dump style.
+		Values are loaded atomically and displayed by default.
+
+	* FloatFormat
+		Format byte, as accepted by strconv.FormatFloat, to use when
+		displaying floating point values.  The 'g' format is used by
+		default.
+
+	* FloatPrecision
+		Number of digits to use when displaying floating point values.  The
+		smallest number of digits necessary to represent the value exactly
+		is used by default.
+
+	* ZeroFloatPrecision
+		Specifies that a FloatPrecision of 0 displays no digits after the
+		decimal point with the 'f' format rather than meaning the default.
+
+	* FloatSpecialAsString
+		Specifies that NaN and infinite floating point values should be
+		displayed as the Go expressions math.NaN() and math.Inf(1) or
+		math.Inf(-1) so output such as that of GoSyntax is valid Go.  They
+		are displayed as NaN, +Inf, and -Inf by default.
+
+	* ComplexFormat
+		Form to display complex numbers in, either ComplexRectangular, such
+		as (3+4i), or ComplexPolar, such as (5∠0.9273) with the phase angle
+		in radians.  Both parts honor FloatPrecision.  Rectangular form is
+		used by default.
+
+	* IntegerBase
+		Base in which to display integer values, such as 16 for hexadecimal
+		with a 0x prefix.  Integers are displayed in base 10 by default.
+
+	* RuneAsChar
+		Specifies that rune (and therefore int32) values should be displayed
+		as a quoted character along with their numeric value.  Runes are
+		displayed as numbers by default.
+
+	* OmitZero
+		Specifies that struct fields and map entries whose values are the
+		zero value for their type should be skipped.  All fields and entries
+		are shown by default.
+
+	* OmitFunc
+		Specifies a callback which is invoked with the path to and value of
+		each struct field, array or slice element, and map value before it
+		is displayed by Dump style output.  The field, element, or entry is
+		skipped when it returns true.  Nothing is omitted by default.
+
+	* BytesAsString
+		Specifies that byte arrays and slices which consist entirely of
+		printable UTF-8 text should be displayed by Dump as a quoted string
+		instead of a hexdump.  Hexdumps are used by default.
+
+	* MaxArrayBytes
+		Maximum number of bytes of byte arrays and slices to hexdump.
+		Larger ones are summarized by their length and their first and last
+		few bytes, such as <32 bytes: 00112233...ccddeeff>.  There is no
+		limit by default.
+
+	* RunesAsString
+		Specifies that rune arrays and slices should be displayed by Dump as
+		a quoted string with non-printable runes escaped instead of a list of
+		code points.  Since rune is an alias for int32, this includes int32
+		arrays and slices.  Lists are used by default.
+
+	* RawStrings
+		Specifies that Dump style output should only escape the control
+		characters, as \xNN bytes, invalid UTF-8, double quotes, and
+		backslashes of strings so all other text is displayed as is.
+		Strings are quoted by strconv.Quote by default.
+
+	* DisableStringQuoting
+		Specifies that Dump style output should display strings verbatim,
+		with the lines of multiline strings indented, instead of quoting
+		them.  Strings are quoted by default.
 
 	* ContinueOnMethod
 		Enables recursion into types after invoking error and Stringer interface
 		methods. Recursion after method invocation is disabled by default.
 
+	* DedupPointers
+		Specifies that the contents of pointers which have already been
+		dumped should not be shown again when the same pointer is reached
+		through another path.  This requires memory proportional to the
+		number of distinct pointers.  Shared pointers are shown in full each
+		time by default.
+
+	* Compact
+		Specifies that Dump and its variants should output each argument on
+		a single line while retaining type information and field names.
+		The usual multi-line format is used by default.
+
+	* EnableColors
+		Enables coloring the output of Dump with ANSI escape sequences.
+		Colors are disabled by default.
+
+	* Colors
+		Specifies the escape sequences to use for each category of token,
+		such as types, field names, strings, numbers, pointers, and nil
+		values, when colors are enabled.  DefaultColors is used by
+		default.
+
+	* JSON
+		Specifies that Dump and its variants should output each argument as
+		a single line of JSON instead of the usual format.  Unexported
+		struct fields are included and circular references are output as
+		{"$ref":"0x..."}.  MaxStringLength, MaxSliceElements,
+		MaxMapEntries, MaxStructFields, and the layout options have no
+		effect on it.  The usual format is used by default.
+
+	* GoSyntax
+		Specifies that Dump and its variants should output each argument as
+		a Go expression, such as pkg.Type{Field: value}, which can be pasted
+		back into source code.  Values which can't be expressed, such as
+		channels and functions, become nil followed by a comment.  The
+		usual format is used by default.
+
+	* Redact
+		Specifies a function which is called with the path to and value of
+		each field, element, and map value.  When it returns true, the
+		returned replacement string is displayed instead of the value.
+		Paths are of the form .Field[idx]["key"].  Nothing is redacted by
+		default.
+
+	* SortFields
+		Specifies struct fields should be sorted by name before being
+		printed.  Fields are printed in declaration order by default.
+
+	* ExportedOnly
+		Specifies that only the exported fields of structs should be
+		displayed, without accessing unexported fields at all.  All fields
+		are displayed by default.
+
+	* ShowFieldTags
+		Specifies that Dump style output should display the raw tag of
+		each struct field after its name, such as Name `json:"name"`.
+		Tags are not displayed by default.
+
+	* MarkEmbedded
+		Specifies that Dump style output should display an <embedded>
+		marker before the name of embedded struct fields, such as
+		<embedded> Base: (main.Base) {...}.  Embedded fields are displayed
+		like any other field by default.
+
+	* ShortTypeNames
+		Specifies that type names should have the directories of package
+		paths removed, such as pkg.Thing instead of
+		github.com/org/proj/pkg.Thing.  Full names are displayed by
+		default.
+
+	* ShowInterfaceType
+		Specifies that Dump style output should display the type of the
+		interface a value is stored in before its concrete type, such as
+		(interface {} = main.Concrete).  Only the concrete type is displayed
+		by default, except for interfaces holding a typed nil, such as
+		(error = *main.MyErr)(<nil>).
+
+	* ShowKind
+		Specifies that Dump style output should display the kind of a value
+		after its type, such as (main.Celsius kind=float64).  Pointers
+		display the kind of the type they ultimately point to.  The kind is
+		not displayed by default.
+
+	* ShowCollectionCounts
+		Specifies that Dump style output should display the total number of
+		entries of arrays, slices, and maps, such as (count=10432), even
+		when only some of them are shown.  Counts are not displayed by
+		default.
+
+	* ShowHeaderAddresses
+		Specifies that Dump style output should display the address of the
+		data referenced by maps, slices, and channels after their type to
+		help diagnose aliasing.  The addresses are not displayed by default.
+
+	* TypedLiterals
+		Specifies that Dump style output should display numbers as
+		conversions to their type, such as uint8(255), in place of the
+		type information normally displayed before them.  Numbers are
+		displayed as plain literals by default.
+
 	* SortKeys
 		Specifies map keys should be sorted before being printed. Use
 		this to have a more deterministic, diffable output.  Note that
-		only native types (bool, int, uint, floats, uintptr and string)
-		and types which implement error or Stringer interfaces are
+		only native types (bool, int, uint, floats, complexes, uintptr and
+		string), pointers, which are sorted by the values they point to,
+		arrays and structs, which are sorted element by element and field
+		by field, and types which implement error or Stringer interfaces are
 		supported with other types sorted according to the
 		reflect.Value.String() output which guarantees display
 		stability.  Natural map order is used by default.
@@ -133,6 +463,18 @@ A third option is to call spew.Sdump to get the formatted output as a string:
 
 	str := spew.Sdump(myVar1, myVar2, ...)
 
+Struct fields which should never be displayed, such as large caches or
+secrets, may be skipped by tagging them with spew:"-" in the same manner as
+the encoding/json package.  This works for unexported fields as well.  The
+tag may also give a field a different name to display instead of its Go
+identifier:
+
+	type Foo struct {
+		Name  string
+		ID    int               `spew:"user_id"`
+		cache map[string][]byte `spew:"-"`
+	}
+
 Sample Dump Output
 
 See the Dump example for details on the setup of the types and variables being
@@ -168,7 +510,9 @@ addresses), %#v (adds types), or %#+v (adds types and pointer addresses) verb
 combinations.  Any other verbs such as %x and %q will be sent to the the
 standard fmt package for formatting.  In addition, the custom formatter ignores
 the width and precision arguments (however they will still work on the format
-specifiers not handled by the custom formatter).
+specifiers not handled by the custom formatter).  When the GoSyntax option is
+set, %#v and %#+v instead output the value as a single line Go expression,
+the same as a compact Dump with GoSyntax set.
 
 Custom Formatter Usage
 
@@ -17,15 +17,20 @@
 package spew
 
 import (
+	"bufio"
 	"bytes"
-	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path"
 	"reflect"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 var (
@@ -45,26 +50,168 @@ var (
 	// cUint8tCharRE is a regular expression that matches a cgo uint8_t.
 	// It is used to detect uint8_t arrays to hexdump them.
 	cUint8tCharRE = regexp.MustCompile(`^.*\._Ctype_uint8_t$`)
+
+	// addressRE is a regular expression that matches a pointer address as
+	// it is displayed.  It is used to normalize the addresses in existing
+	// output.
+	addressRE = regexp.MustCompile(`\b0x[0-9a-f]+\b`)
 )
 
+// Dumper is the interface implemented by types which know how to display
+// themselves for debugging better than their error or Stringer methods or
+// their fields would.  Dump and its variants invoke SpewDump, unless the
+// DisableDumperInterface option is set, instead of displaying the value
+// themselves.  SpewDump is passed the configuration in use and should write
+// the value's representation to w.  The type and any length and capacity have
+// already been displayed at that point.
+type Dumper interface {
+	SpewDump(w io.Writer, cfg *ConfigState)
+}
+
+// FormatterFunc is the type of the functions registered with
+// ConfigState.RegisterFormatter to display values of a specific type.  Like
+// SpewDump, it is passed the configuration in use and should write the value's
+// representation to w after the type and any length and capacity have already
+// been displayed.
+type FormatterFunc func(w io.Writer, v reflect.Value, cfg *ConfigState)
+
 // dumpState contains information about the state of a dump operation.
 type dumpState struct {
 	w                io.Writer
 	depth            int
 	pointers         map[uintptr]int
+	dumped           map[uintptr]bool
+	aliases          map[uintptr]int
+	path             string
 	ignoreNextType   bool
 	ignoreNextIndent bool
+	ifaceType        reflect.Type
+	colors           Colors
+	indentation      []byte
+	budget           *budgetWriter
 	cs               *ConfigState
 }
 
+// pointersPool holds the maps used to detect circular references so they can
+// be reused between dumps rather than allocated for each one.
+var pointersPool = sync.Pool{
+	New: func() interface{} {
+		return make(map[uintptr]int)
+	},
+}
+
 // indent performs indentation according to the depth level and cs.Indent
-// option.
+// option.  No indentation is performed in compact mode.
 func (d *dumpState) indent() {
 	if d.ignoreNextIndent {
 		d.ignoreNextIndent = false
 		return
 	}
-	d.w.Write(bytes.Repeat([]byte(d.cs.Indent), d.depth))
+	if d.cs.Compact {
+		return
+	}
+
+	// The indentation for each level is a prefix of the indentation for the
+	// deepest level reached so far, so it is only grown as needed in order to
+	// avoid allocating on every line.
+	n := d.depth * len(d.cs.Indent)
+	for len(d.indentation) < n {
+		d.indentation = append(d.indentation, d.cs.Indent...)
+	}
+	d.w.Write(d.indentation[:n])
+}
+
+// writeVerbatim writes the passed string as is, except that the lines after
+// the first are indented one level deeper than the current depth so they don't
+// break the indentation of the surrounding output.
+func (d *dumpState) writeVerbatim(s string) {
+	if !d.cs.Compact {
+		indent := strings.Repeat(d.cs.Indent, d.depth+1)
+		s = strings.Replace(s, "\n", "\n"+indent, -1)
+	}
+	d.w.Write([]byte(s))
+}
+
+// newline writes a newline unless in compact mode.
+func (d *dumpState) newline() {
+	if !d.cs.Compact {
+		d.w.Write(newlineBytes)
+	}
+}
+
+// space writes a space unless in compact mode.
+func (d *dumpState) space() {
+	if !d.cs.Compact {
+		d.w.Write(spaceBytes)
+	}
+}
+
+// printPtr outputs the passed address, or the id assigned to it when pointer
+// aliases are enabled.  Ids are assigned sequentially starting from 1 in the
+// order addresses are first encountered.
+func (d *dumpState) printPtr(addr uintptr) {
+	if d.aliases != nil && addr != 0 {
+		id, ok := d.aliases[addr]
+		if !ok {
+			id = len(d.aliases) + 1
+			d.aliases[addr] = id
+		}
+		addr = uintptr(id)
+	}
+	printPtrOrNil(d.cs, d.w, addr)
+}
+
+// openBrace writes the opening brace of a composite value along with the
+// newline which precedes its entries.
+func (d *dumpState) openBrace() {
+	d.w.Write(openBraceBytes)
+	d.newline()
+}
+
+// colon writes the separator between a struct field name or map key and its
+// value.
+func (d *dumpState) colon() {
+	if d.cs.Compact {
+		d.w.Write(colonBytes)
+		return
+	}
+	d.w.Write(colonSpaceBytes)
+}
+
+// endEntry writes the separator which follows an entry of a composite value.
+// The last entry is only followed by a newline.
+func (d *dumpState) endEntry(last bool) {
+	switch {
+	case last:
+		d.newline()
+	case d.cs.Compact:
+		d.w.Write(commaSpaceBytes)
+	default:
+		d.w.Write(commaNewlineBytes)
+	}
+}
+
+// maxDepth writes the marker which indicates the maximum depth was reached.
+func (d *dumpState) maxDepth() {
+	d.indent()
+	d.w.Write(maxDepthBytes)
+	d.newline()
+}
+
+// startColor begins coloring the output with the passed ANSI escape sequence.
+// Nothing is written when colors are disabled since the color will be empty.
+func (d *dumpState) startColor(color string) {
+	if color != "" {
+		io.WriteString(d.w, color)
+	}
+}
+
+// endColor resets the output color after a token which was started with
+// startColor and the same color.
+func (d *dumpState) endColor(color string) {
+	if color != "" {
+		d.w.Write(colorResetBytes)
+	}
 }
 
 // unpackValue returns values inside of non-nil interfaces when possible.
@@ -72,11 +219,154 @@ func (d *dumpState) indent() {
 // can contain varying types packed inside an interface.
 func (d *dumpState) unpackValue(v reflect.Value) reflect.Value {
 	if v.Kind() == reflect.Interface && !v.IsNil() {
+		if d.cs.ShowInterfaceType || isTypedNil(v.Elem()) {
+			d.ifaceType = v.Type()
+		}
 		v = v.Elem()
 	}
 	return v
 }
 
+// isTypedNil returns whether or not the passed value, which is held by an
+// interface, is a nil pointer, map, slice, channel, or function.  An interface
+// holding such a value is itself not nil, which is a common source of bugs,
+// such as functions that return a nil *MyErr as an error.
+func isTypedNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// writeType writes the type information displayed before a value, which is
+// the type of the passed value in parentheses followed by a space.
+func (d *dumpState) writeType(v reflect.Value) {
+	d.w.Write(openParenBytes)
+	d.writeIfaceType()
+	d.startColor(d.colors.Type)
+	d.w.Write([]byte(d.cs.typeName(v.Type())))
+	d.endColor(d.colors.Type)
+	d.writeKind(v.Type())
+	d.w.Write(closeParenBytes)
+	d.writeHeaderAddr(v)
+	d.space()
+}
+
+// writeHeaderAddr writes the address of the data referenced by the passed map,
+// slice, or channel, when the ShowHeaderAddresses option is set, which shows
+// whether values share the same underlying data.
+func (d *dumpState) writeHeaderAddr(v reflect.Value) {
+	if !d.cs.ShowHeaderAddresses || d.cs.DisablePointerAddresses {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Chan:
+		if v.IsNil() {
+			return
+		}
+		d.w.Write(openParenBytes)
+		d.startColor(d.colors.Pointer)
+		d.printPtr(v.Pointer())
+		d.endColor(d.colors.Pointer)
+		d.w.Write(closeParenBytes)
+	}
+}
+
+// writeKind writes the kind of the passed type, or the type it ultimately
+// points to for pointer types, when the ShowKind option is set.
+func (d *dumpState) writeKind(t reflect.Type) {
+	if !d.cs.ShowKind {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	d.w.Write(kindEqualsBytes)
+	d.w.Write([]byte(t.Kind().String()))
+}
+
+// writeIfaceType writes the type of the interface the value being dumped was
+// unpacked from followed by an equals sign, when the ShowInterfaceType option
+// is set, so it precedes the concrete type of the value.
+func (d *dumpState) writeIfaceType() {
+	if d.ifaceType == nil {
+		return
+	}
+	d.startColor(d.colors.Type)
+	d.w.Write([]byte(d.cs.typeName(d.ifaceType)))
+	d.endColor(d.colors.Type)
+	d.w.Write(ifaceEqualsBytes)
+	d.ifaceType = nil
+}
+
+// tracksPaths returns whether or not the paths to values need to be tracked,
+// which is only the case when they are passed to a Redact or OmitFunc
+// callback.
+func (d *dumpState) tracksPaths() bool {
+	return d.cs.Redact != nil || d.cs.OmitFunc != nil
+}
+
+// dumpPath dumps the passed value which is located at the passed path within
+// the value being dumped.  When a Redact callback is configured, it is invoked
+// with the path and value, and the replacement it returns, if any, is
+// displayed in place of the value.
+func (d *dumpState) dumpPath(path string, v reflect.Value) {
+	if !d.tracksPaths() {
+		d.dump(v)
+		return
+	}
+
+	if d.cs.Redact != nil {
+		if replacement, ok := d.cs.Redact(path, v); ok {
+			d.indent()
+			if !d.ignoreNextType && v.IsValid() {
+				d.writeType(v)
+			}
+			d.ignoreNextType = false
+			d.ifaceType = nil
+			d.w.Write([]byte(replacement))
+			return
+		}
+	}
+
+	parentPath := d.path
+	d.path = path
+	d.dump(v)
+	d.path = parentPath
+}
+
+// dumpField dumps the passed value of the named struct field.  The path to the
+// field is only built when it is needed for redaction or omission.
+func (d *dumpState) dumpField(name string, v reflect.Value) {
+	if !d.tracksPaths() {
+		d.dump(v)
+		return
+	}
+	d.dumpPath(fieldPath(d.path, name), v)
+}
+
+// dumpIndex dumps the passed value of the element at index i of an array or
+// slice.  The path to the element is only built when it is needed for
+// redaction or omission.
+func (d *dumpState) dumpIndex(i int, v reflect.Value) {
+	if !d.tracksPaths() {
+		d.dump(v)
+		return
+	}
+	d.dumpPath(indexPath(d.path, i), v)
+}
+
+// dumpMapValue dumps the passed value of the map entry for the passed key.  The
+// path to the entry is only built when it is needed for redaction or omission.
+func (d *dumpState) dumpMapValue(key, v reflect.Value) {
+	if !d.tracksPaths() {
+		d.dump(v)
+		return
+	}
+	d.dumpPath(keyPath(d.cs, d.path, key), v)
+}
+
 // dumpPtr handles formatting of pointers by indirecting them as necessary.
 func (d *dumpState) dumpPtr(v reflect.Value) {
 	// Remove pointers at or below the current depth from map used to detect
@@ -87,6 +377,26 @@ func (d *dumpState) dumpPtr(v reflect.Value) {
 		}
 	}
 
+	// Only display the type and address of non-nil pointers when following
+	// them is disabled.
+	if d.cs.DisablePointerFollowing && !v.IsNil() {
+		d.w.Write(openParenBytes)
+		d.writeIfaceType()
+		d.startColor(d.colors.Type)
+		d.w.Write([]byte(d.cs.typeName(v.Type())))
+		d.endColor(d.colors.Type)
+		d.writeKind(v.Type())
+		d.w.Write(closeParenBytes)
+		if !d.cs.DisablePointerAddresses {
+			d.w.Write(openParenBytes)
+			d.startColor(d.colors.Pointer)
+			d.printPtr(v.Pointer())
+			d.endColor(d.colors.Pointer)
+			d.w.Write(closeParenBytes)
+		}
+		return
+	}
+
 	// Keep list of all dereferenced pointers to show later.
 	pointerChain := make([]uintptr, 0)
 
@@ -95,6 +405,7 @@ func (d *dumpState) dumpPtr(v reflect.Value) {
 	// references.
 	nilFound := false
 	cycleFound := false
+	dupFound := false
 	indirects := 0
 	ve := v
 	for ve.Kind() == reflect.Ptr {
@@ -104,12 +415,22 @@ func (d *dumpState) dumpPtr(v reflect.Value) {
 		}
 		indirects++
 		addr := ve.Pointer()
-		pointerChain = append(pointerChain, addr)
+		if d.cs.MaxPointerChain <= 0 || len(pointerChain) <= d.cs.MaxPointerChain {
+			pointerChain = append(pointerChain, addr)
+		}
 		if pd, ok := d.pointers[addr]; ok && pd < d.depth {
 			cycleFound = true
 			indirects--
 			break
 		}
+		if d.dumped != nil {
+			if d.dumped[addr] {
+				dupFound = true
+				indirects--
+				break
+			}
+			d.dumped[addr] = true
+		}
 		d.pointers[addr] = d.depth
 
 		ve = ve.Elem()
@@ -124,8 +445,12 @@ func (d *dumpState) dumpPtr(v reflect.Value) {
 
 	// Display type information.
 	d.w.Write(openParenBytes)
+	d.writeIfaceType()
+	d.startColor(d.colors.Type)
 	d.w.Write(bytes.Repeat(asteriskBytes, indirects))
-	d.w.Write([]byte(ve.Type().String()))
+	d.w.Write([]byte(d.cs.typeName(ve.Type())))
+	d.endColor(d.colors.Type)
+	d.writeKind(ve.Type())
 	d.w.Write(closeParenBytes)
 
 	// Display pointer information.
@@ -135,7 +460,13 @@ func (d *dumpState) dumpPtr(v reflect.Value) {
 			if i > 0 {
 				d.w.Write(pointerChainBytes)
 			}
-			printHexPtr(d.w, addr)
+			if i > 0 && i == d.cs.MaxPointerChain {
+				d.w.Write(ellipsisBytes)
+				break
+			}
+			d.startColor(d.colors.Pointer)
+			d.printPtr(addr)
+			d.endColor(d.colors.Pointer)
 		}
 		d.w.Write(closeParenBytes)
 	}
@@ -144,10 +475,15 @@ func (d *dumpState) dumpPtr(v reflect.Value) {
 	d.w.Write(openParenBytes)
 	switch {
 	case nilFound:
-		d.w.Write(nilAngleBytes)
+		d.startColor(d.colors.Nil)
+		d.w.Write(d.cs.nilMarker())
+		d.endColor(d.colors.Nil)
 
 	case cycleFound:
-		d.w.Write(circularBytes)
+		d.w.Write(d.cs.circularMarker(circularBytes))
+
+	case dupFound:
+		d.w.Write(dumpedBytes)
 
 	default:
 		d.ignoreNextType = true
@@ -156,91 +492,175 @@ func (d *dumpState) dumpPtr(v reflect.Value) {
 	d.w.Write(closeParenBytes)
 }
 
+// byteSlice returns the contents of the passed array or slice as a uint8 slice
+// along with whether or not it is a type that should be treated as raw bytes.
+// Byte (uint8 under reflection) arrays and slices as well as the various cgo
+// char types qualify.  The underlying data is used directly when possible and
+// is otherwise converted and copied.
+func byteSlice(v reflect.Value) ([]uint8, bool) {
+	numEntries := v.Len()
+	if numEntries == 0 {
+		return nil, false
+	}
+
+	doConvert := false
+	vt := v.Index(0).Type()
+	vts := vt.String()
+	switch {
+	// C types that need to be converted.
+	case cCharRE.MatchString(vts):
+		fallthrough
+	case cUnsignedCharRE.MatchString(vts):
+		fallthrough
+	case cUint8tCharRE.MatchString(vts):
+		doConvert = true
+
+	// Try to use existing uint8 slices and fall back to converting
+	// and copying if that fails.
+	case vt.Kind() == reflect.Uint8:
+		// We need an addressable interface to convert the type
+		// to a byte slice.  However, the reflect package won't
+		// give us an interface on certain things like
+		// unexported struct fields in order to enforce
+		// visibility rules.  We use unsafe, when available, to
+		// bypass these restrictions since this package does not
+		// mutate the values.
+		vs := v
+		if !vs.CanInterface() || !vs.CanAddr() {
+			vs = unsafeReflectValue(vs)
+		}
+		if !UnsafeDisabled {
+			vs = vs.Slice(0, numEntries)
+
+			// Use the existing uint8 slice if it can be
+			// type asserted.
+			iface := vs.Interface()
+			if slice, ok := iface.([]uint8); ok {
+				return slice, true
+			}
+		}
+
+		// The underlying data needs to be converted if it can't
+		// be type asserted to a uint8 slice.
+		doConvert = true
+	}
+
+	// Copy and convert the underlying type if needed.
+	if doConvert && vt.ConvertibleTo(uint8Type) {
+		// Convert and copy each element into a uint8 byte
+		// slice.
+		buf := make([]uint8, numEntries)
+		for i := 0; i < numEntries; i++ {
+			vv := v.Index(i)
+			buf[i] = uint8(vv.Convert(uint8Type).Uint())
+		}
+		return buf, true
+	}
+	return nil, false
+}
+
+// isCollection returns whether or not the passed value is a non-nil array,
+// slice, or map.
+func isCollection(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array:
+		return true
+	case reflect.Slice, reflect.Map:
+		return !v.IsNil()
+	}
+	return false
+}
+
+// runeSlice returns the runes of the passed value when it is a non-empty rune
+// array or slice made up entirely of valid Unicode code points.  Since rune is
+// an alias for int32, int32 arrays and slices qualify as well.
+func runeSlice(v reflect.Value) ([]rune, bool) {
+	numEntries := v.Len()
+	if numEntries == 0 || v.Type().Elem() != runeType {
+		return nil, false
+	}
+
+	runes := make([]rune, numEntries)
+	for i := 0; i < numEntries; i++ {
+		r := rune(v.Index(i).Int())
+		if !utf8.ValidRune(r) {
+			return nil, false
+		}
+		runes[i] = r
+	}
+	return runes, true
+}
+
 // dumpSlice handles formatting of arrays and slices.  Byte (uint8 under
 // reflection) arrays and slices are dumped in hexdump -C fashion.
 func (d *dumpState) dumpSlice(v reflect.Value) {
-	// Determine whether this type should be hex dumped or not.  Also,
-	// for types which should be hexdumped, try to use the underlying data
-	// first, then fall back to trying to convert them to a uint8 slice.
-	var buf []uint8
-	doConvert := false
-	doHexDump := false
+	// Determine whether this type should be hex dumped or not.
 	numEntries := v.Len()
-	if numEntries > 0 {
-		vt := v.Index(0).Type()
-		vts := vt.String()
-		switch {
-		// C types that need to be converted.
-		case cCharRE.MatchString(vts):
-			fallthrough
-		case cUnsignedCharRE.MatchString(vts):
-			fallthrough
-		case cUint8tCharRE.MatchString(vts):
-			doConvert = true
-
-		// Try to use existing uint8 slices and fall back to converting
-		// and copying if that fails.
-		case vt.Kind() == reflect.Uint8:
-			// We need an addressable interface to convert the type
-			// to a byte slice.  However, the reflect package won't
-			// give us an interface on certain things like
-			// unexported struct fields in order to enforce
-			// visibility rules.  We use unsafe, when available, to
-			// bypass these restrictions since this package does not
-			// mutate the values.
-			vs := v
-			if !vs.CanInterface() || !vs.CanAddr() {
-				vs = unsafeReflectValue(vs)
-			}
-			if !UnsafeDisabled {
-				vs = vs.Slice(0, numEntries)
-
-				// Use the existing uint8 slice if it can be
-				// type asserted.
-				iface := vs.Interface()
-				if slice, ok := iface.([]uint8); ok {
-					buf = slice
-					doHexDump = true
-					break
-				}
-			}
+	buf, doHexDump := byteSlice(v)
 
-			// The underlying data needs to be converted if it can't
-			// be type asserted to a uint8 slice.
-			doConvert = true
-		}
+	// Limit the number of elements shown when requested.
+	numShown := limitEntries(numEntries, d.cs.MaxSliceElements)
 
-		// Copy and convert the underlying type if needed.
-		if doConvert && vt.ConvertibleTo(uint8Type) {
-			// Convert and copy each element into a uint8 byte
-			// slice.
-			buf = make([]uint8, numEntries)
-			for i := 0; i < numEntries; i++ {
-				vv := v.Index(i)
-				buf[i] = uint8(vv.Convert(uint8Type).Uint())
+	// Hexdump the entire slice as needed.  Compact mode uses space separated
+	// hex bytes on a single line instead.
+	if doHexDump && d.cs.Compact {
+		for i, b := range buf[:numShown] {
+			if i > 0 {
+				d.w.Write(spaceBytes)
 			}
-			doHexDump = true
+			d.w.Write([]byte{hexDigits[b>>4], hexDigits[b&0x0f]})
+		}
+		if numShown < numEntries {
+			d.w.Write(spaceBytes)
+			printMore(d.w, numEntries-numShown, moreElementsBytes)
 		}
+		return
 	}
-
-	// Hexdump the entire slice as needed.
 	if doHexDump {
-		indent := strings.Repeat(d.cs.Indent, d.depth)
-		str := indent + hex.Dump(buf)
-		str = strings.Replace(str, "\n", "\n"+indent, -1)
-		str = strings.TrimRight(str, d.cs.Indent)
-		d.w.Write([]byte(str))
+		var dump bytes.Buffer
+		writeByteDump(&dump, buf[:numShown], d.cs.bytesPerLine())
+
+		// Indent each line of the hexdump in place rather than building an
+		// indented copy of it.
+		lines := dump.Bytes()
+		for len(lines) > 0 {
+			end := bytes.IndexByte(lines, '\n') + 1
+			if end == 0 {
+				end = len(lines)
+			}
+			d.indent()
+			d.w.Write(lines[:end])
+			lines = lines[end:]
+		}
+		if numShown < numEntries {
+			d.indent()
+			printMore(d.w, numEntries-numShown, moreElementsBytes)
+			d.w.Write(newlineBytes)
+		}
 		return
 	}
 
+	// Skip the elements omitted by the OmitFunc option.
+	var indices []int
+	if d.cs.OmitFunc != nil {
+		indices = shownIndices(d.cs, d.path, v)
+		numEntries = len(indices)
+		numShown = limitEntries(numEntries, d.cs.MaxSliceElements)
+	}
+
 	// Recursively call dump for each item.
-	for i := 0; i < numEntries; i++ {
-		d.dump(d.unpackValue(v.Index(i)))
-		if i < (numEntries - 1) {
-			d.w.Write(commaNewlineBytes)
-		} else {
-			d.w.Write(newlineBytes)
+	for n := 0; n < numShown; n++ {
+		i := n
+		if indices != nil {
+			i = indices[n]
 		}
+		d.dumpIndex(i, d.unpackValue(v.Index(i)))
+		d.endEntry(n == numEntries-1)
+	}
+	if numShown < numEntries {
+		d.indent()
+		printMore(d.w, numEntries-numShown, moreElementsBytes)
+		d.newline()
 	}
 }
 
@@ -249,6 +669,11 @@ func (d *dumpState) dumpSlice(v reflect.Value) {
 // appropriately.  It is a recursive function, however circular data structures
 // are detected and handled properly.
 func (d *dumpState) dump(v reflect.Value) {
+	// Stop descending once the maximum output size has been reached.
+	if d.budget != nil && d.budget.exhausted {
+		return
+	}
+
 	// Handle invalid reflect values immediately.
 	kind := v.Kind()
 	if kind == reflect.Invalid {
@@ -263,25 +688,71 @@ func (d *dumpState) dump(v reflect.Value) {
 		return
 	}
 
-	// Print type information unless already handled elsewhere.
+	// Display the value a reflect.Value represents, including its type, rather
+	// than the internals of the reflect.Value itself.
+	if v.Type() == reflectValueType {
+		if rv, ok := unpackReflectValue(v); ok {
+			d.ignoreNextType = false
+			d.ifaceType = nil
+			if !rv.IsValid() {
+				d.indent()
+			}
+			d.dump(rv)
+			return
+		}
+	}
+
+	// Print type information unless already handled elsewhere.  Numbers
+	// displayed as typed literals already include their type, so it is only
+	// printed for them when their methods display them instead.
+	typedLiteral := d.cs.TypedLiterals && isNumber(kind)
+	deferType := typedLiteral && !d.ignoreNextType
 	if !d.ignoreNextType {
 		d.indent()
-		d.w.Write(openParenBytes)
-		d.w.Write([]byte(v.Type().String()))
-		d.w.Write(closeParenBytes)
-		d.w.Write(spaceBytes)
+		if !deferType {
+			d.writeType(v)
+		}
 	}
 	d.ignoreNextType = false
+	d.ifaceType = nil
+
+	// Display the entries of a sync.Map like a regular map rather than its
+	// internals.
+	if v.Type() == syncMapType && !d.cs.DisableSyncMapExpansion {
+		if contents, ok := syncMapContents(v); ok {
+			v, kind = contents, reflect.Map
+		}
+	}
+
+	// Display the current value of sync/atomic types rather than their
+	// internals.  The values held by atomic.Value and atomic.Pointer are of
+	// arbitrary types, so they are displayed along with their own type.
+	if !d.cs.DisableAtomicExpansion {
+		if loaded, ok := atomicLoad(v); ok {
+			switch loaded.Kind() {
+			case reflect.Interface, reflect.Ptr:
+				d.ignoreNextIndent = true
+				d.dump(d.unpackValue(loaded))
+				return
+			}
+			v, kind = loaded, loaded.Kind()
+		}
+	}
 
 	// Display length and capacity if the built-in len and cap functions
-	// work with the value's kind and the len/cap itself is non-zero.
+	// work with the value's kind and the len/cap itself is non-zero.  The
+	// capacity of an array is always its length, so only the length is
+	// displayed for them.
 	valueLen, valueCap := 0, 0
 	switch v.Kind() {
-	case reflect.Array, reflect.Slice, reflect.Chan:
+	case reflect.Slice, reflect.Chan:
 		valueLen, valueCap = v.Len(), v.Cap()
-	case reflect.Map, reflect.String:
+	case reflect.Array, reflect.Map, reflect.String:
 		valueLen = v.Len()
 	}
+	if d.cs.DisableLengths {
+		valueLen = 0
+	}
 	if valueLen != 0 || !d.cs.DisableCapacities && valueCap != 0 {
 		d.w.Write(openParenBytes)
 		if valueLen != 0 {
@@ -296,16 +767,76 @@ func (d *dumpState) dump(v reflect.Value) {
 			printInt(d.w, int64(valueCap), 10)
 		}
 		d.w.Write(closeParenBytes)
-		d.w.Write(spaceBytes)
+		d.space()
+	}
+
+	// Display the total number of entries of collections when requested.
+	// Unlike the length, it is displayed even when the options which limit
+	// the number of entries shown or hide lengths are set.
+	if d.cs.ShowCollectionCounts && isCollection(v) {
+		d.w.Write(openParenBytes)
+		d.w.Write(countEqualsBytes)
+		printInt(d.w, int64(v.Len()), 10)
+		d.w.Write(closeParenBytes)
+		d.space()
+	}
+
+	// The output of methods is buffered while the type information is
+	// deferred since it must precede the output when they handle the value.
+	methodW := d.w
+	var methodBuf bytes.Buffer
+	if deferType {
+		methodW = &methodBuf
+	}
+
+	// Display values of types with a registered formatter with it.  Failing
+	// that, let types which implement the Dumper interface display themselves
+	// unless doing so is disabled.
+	handled := handleFormatter(d.cs, methodW, v)
+	if !handled && !d.cs.DisableDumperInterface {
+		if (kind != reflect.Invalid) && (kind != reflect.Interface) {
+			handled = handleDumper(d.cs, methodW, v)
+		}
 	}
 
 	// Call Stringer/error interfaces if they exist and the handle methods flag
 	// is enabled
-	if !d.cs.DisableMethods {
+	if !handled && !d.cs.DisableMethods {
 		if (kind != reflect.Invalid) && (kind != reflect.Interface) {
-			if handled := handleMethods(d.cs, d.w, v); handled {
-				return
-			}
+			handled = handleMethods(d.cs, methodW, v)
+		}
+	}
+	if deferType {
+		if handled {
+			d.writeType(v)
+		}
+		d.w.Write(methodBuf.Bytes())
+	}
+	if handled {
+		return
+	}
+
+	// Detect slices and maps which contain themselves.  Unlike pointers, the
+	// data they reference is only tracked while it is being dumped since it
+	// is commonly shared, such as by subslices, without being circular.
+	if addr := refAddr(v); addr != 0 {
+		if pd, ok := d.pointers[addr]; ok && pd < d.depth {
+			d.w.Write(d.cs.circularMarker(circularBytes))
+			return
+		}
+		d.pointers[addr] = d.depth
+		defer delete(d.pointers, addr)
+	}
+
+	// Wrap numbers displayed as typed literals in a conversion to their type.
+	// Complex numbers are already parenthesized.
+	if typedLiteral {
+		d.startColor(d.colors.Type)
+		d.w.Write([]byte(d.cs.typeName(v.Type())))
+		d.endColor(d.colors.Type)
+		if kind != reflect.Complex64 && kind != reflect.Complex128 {
+			d.w.Write(openParenBytes)
+			defer d.w.Write(closeParenBytes)
 		}
 	}
 
@@ -318,36 +849,107 @@ func (d *dumpState) dump(v reflect.Value) {
 		printBool(d.w, v.Bool())
 
 	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
-		printInt(d.w, v.Int(), 10)
+		d.startColor(d.colors.Number)
+		if char, ok := runeChar(v.Int()); ok && d.cs.RuneAsChar && v.Type() == runeType {
+			// The character alone is a valid literal for a typed rune.
+			d.w.Write([]byte(char))
+			if !typedLiteral {
+				d.w.Write(spaceBytes)
+				d.w.Write(openParenBytes)
+				printInt(d.w, v.Int(), d.cs.intBase())
+				d.w.Write(closeParenBytes)
+			}
+		} else {
+			printInt(d.w, v.Int(), d.cs.intBase())
+		}
+		d.endColor(d.colors.Number)
 
 	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
-		printUint(d.w, v.Uint(), 10)
+		d.startColor(d.colors.Number)
+		printUint(d.w, v.Uint(), d.cs.intBase())
+		d.endColor(d.colors.Number)
 
 	case reflect.Float32:
-		printFloat(d.w, v.Float(), 32)
+		d.startColor(d.colors.Number)
+		printFloat(d.w, v.Float(), 32, d.cs)
+		d.endColor(d.colors.Number)
 
 	case reflect.Float64:
-		printFloat(d.w, v.Float(), 64)
+		d.startColor(d.colors.Number)
+		printFloat(d.w, v.Float(), 64, d.cs)
+		d.endColor(d.colors.Number)
 
 	case reflect.Complex64:
-		printComplex(d.w, v.Complex(), 32)
+		d.startColor(d.colors.Number)
+		printComplex(d.w, v.Complex(), 32, d.cs)
+		d.endColor(d.colors.Number)
 
 	case reflect.Complex128:
-		printComplex(d.w, v.Complex(), 64)
+		d.startColor(d.colors.Number)
+		printComplex(d.w, v.Complex(), 64, d.cs)
+		d.endColor(d.colors.Number)
 
 	case reflect.Slice:
 		if v.IsNil() {
-			d.w.Write(nilAngleBytes)
+			d.startColor(d.colors.Nil)
+			d.w.Write(d.cs.nilMarker())
+			d.endColor(d.colors.Nil)
 			break
 		}
 		fallthrough
 
 	case reflect.Array:
-		d.w.Write(openBraceNewlineBytes)
+		// Display byte arrays and slices that consist entirely of
+		// printable text as a quoted string when requested.
+		if d.cs.BytesAsString {
+			if buf, ok := byteSlice(v); ok && isPrintableText(buf) {
+				str := string(buf)
+				shown, truncated := truncateString(str, d.cs.MaxStringLength)
+				d.startColor(d.colors.String)
+				d.w.Write([]byte(strconv.Quote(shown)))
+				d.endColor(d.colors.String)
+				if truncated {
+					printTruncated(d.w, len(str))
+				}
+				break
+			}
+		}
+
+		// Summarize byte arrays and slices which are larger than requested
+		// instead of hexdumping them.
+		if d.cs.MaxArrayBytes > 0 && v.Len() > d.cs.MaxArrayBytes {
+			if buf, ok := byteSlice(v); ok {
+				writeByteSummary(d.w, buf)
+				break
+			}
+		}
+
+		// Display rune arrays and slices as a quoted string when requested.
+		// Non-printable runes are escaped.
+		if d.cs.RunesAsString {
+			if runes, ok := runeSlice(v); ok {
+				str := string(runes)
+				shown, truncated := truncateString(str, d.cs.MaxStringLength)
+				d.startColor(d.colors.String)
+				d.w.Write([]byte(quoteString(shown, d.cs.RawStrings)))
+				d.endColor(d.colors.String)
+				if truncated {
+					printTruncated(d.w, len(str))
+				}
+				break
+			}
+		}
+
+		// Empty arrays and slices are displayed on a single line.
+		if v.Len() == 0 {
+			d.w.Write(emptyBracesBytes)
+			break
+		}
+
+		d.openBrace()
 		d.depth++
-		if (d.cs.MaxDepth != 0) && (d.depth > d.cs.MaxDepth) {
-			d.indent()
-			d.w.Write(maxNewlineBytes)
+		if d.cs.depthExceeded(d.depth) {
+			d.maxDepth()
 		} else {
 			d.dumpSlice(v)
 		}
@@ -356,13 +958,26 @@ func (d *dumpState) dump(v reflect.Value) {
 		d.w.Write(closeBraceBytes)
 
 	case reflect.String:
-		d.w.Write([]byte(strconv.Quote(v.String())))
+		str := v.String()
+		shown, truncated := truncateString(str, d.cs.MaxStringLength)
+		d.startColor(d.colors.String)
+		if d.cs.DisableStringQuoting {
+			d.writeVerbatim(shown)
+		} else {
+			d.w.Write([]byte(quoteString(shown, d.cs.RawStrings)))
+		}
+		d.endColor(d.colors.String)
+		if truncated {
+			printTruncated(d.w, len(str))
+		}
 
 	case reflect.Interface:
 		// The only time we should get here is for nil interfaces due to
 		// unpackValue calls.
 		if v.IsNil() {
-			d.w.Write(nilAngleBytes)
+			d.startColor(d.colors.Nil)
+			d.w.Write(d.cs.nilMarker())
+			d.endColor(d.colors.Nil)
 		}
 
 	case reflect.Ptr:
@@ -372,31 +987,46 @@ func (d *dumpState) dump(v reflect.Value) {
 	case reflect.Map:
 		// nil maps should be indicated as different than empty maps
 		if v.IsNil() {
-			d.w.Write(nilAngleBytes)
+			d.startColor(d.colors.Nil)
+			d.w.Write(d.cs.nilMarker())
+			d.endColor(d.colors.Nil)
 			break
 		}
 
-		d.w.Write(openBraceNewlineBytes)
+		// Empty maps are displayed on a single line.
+		if v.Len() == 0 {
+			d.w.Write(emptyBracesBytes)
+			break
+		}
+
+		d.openBrace()
 		d.depth++
-		if (d.cs.MaxDepth != 0) && (d.depth > d.cs.MaxDepth) {
-			d.indent()
-			d.w.Write(maxNewlineBytes)
+		if d.cs.depthExceeded(d.depth) {
+			d.maxDepth()
 		} else {
-			numEntries := v.Len()
 			keys := v.MapKeys()
+			if d.cs.OmitZero {
+				keys = nonZeroKeys(v, keys)
+			}
+			if d.cs.OmitFunc != nil {
+				keys = shownKeys(d.cs, d.path, v, keys)
+			}
 			if d.cs.SortKeys {
 				sortValues(keys, d.cs)
 			}
-			for i, key := range keys {
+			numEntries := len(keys)
+			numShown := limitEntries(numEntries, d.cs.MaxMapEntries)
+			for i, key := range keys[:numShown] {
 				d.dump(d.unpackValue(key))
-				d.w.Write(colonSpaceBytes)
+				d.colon()
 				d.ignoreNextIndent = true
-				d.dump(d.unpackValue(v.MapIndex(key)))
-				if i < (numEntries - 1) {
-					d.w.Write(commaNewlineBytes)
-				} else {
-					d.w.Write(newlineBytes)
-				}
+				d.dumpMapValue(key, d.unpackValue(v.MapIndex(key)))
+				d.endEntry(i == numEntries-1)
+			}
+			if numShown < numEntries {
+				d.indent()
+				printMore(d.w, numEntries-numShown, moreEntriesBytes)
+				d.newline()
 			}
 		}
 		d.depth--
@@ -404,26 +1034,57 @@ func (d *dumpState) dump(v reflect.Value) {
 		d.w.Write(closeBraceBytes)
 
 	case reflect.Struct:
-		d.w.Write(openBraceNewlineBytes)
+		if handleKnownTypes(d.cs, d.w, v) {
+			break
+		}
+
+		// Structs without any fields to display are displayed on a single
+		// line.
+		vt := v.Type()
+		fields := visibleFields(d.cs, vt)
+		if d.cs.OmitZero {
+			fields = nonZeroFields(v, fields)
+		}
+		if d.cs.OmitFunc != nil {
+			fields = shownFields(d.cs, d.path, v, fields)
+		}
+		if len(fields) == 0 {
+			d.w.Write(emptyBracesBytes)
+			break
+		}
+
+		d.openBrace()
 		d.depth++
-		if (d.cs.MaxDepth != 0) && (d.depth > d.cs.MaxDepth) {
-			d.indent()
-			d.w.Write(maxNewlineBytes)
+		if d.cs.depthExceeded(d.depth) {
+			d.maxDepth()
 		} else {
-			vt := v.Type()
-			numFields := v.NumField()
-			for i := 0; i < numFields; i++ {
+			numEntries := len(fields)
+			numShown := limitEntries(numEntries, d.cs.MaxStructFields)
+			for i, fi := range fields[:numShown] {
 				d.indent()
-				vtf := vt.Field(i)
-				d.w.Write([]byte(vtf.Name))
-				d.w.Write(colonSpaceBytes)
-				d.ignoreNextIndent = true
-				d.dump(d.unpackValue(v.Field(i)))
-				if i < (numFields - 1) {
-					d.w.Write(commaNewlineBytes)
-				} else {
-					d.w.Write(newlineBytes)
+				vtf := vt.Field(fi)
+				name := fieldName(vtf)
+				if d.cs.MarkEmbedded && vtf.Anonymous {
+					d.w.Write(embeddedBytes)
+				}
+				d.startColor(d.colors.FieldName)
+				d.w.Write([]byte(name))
+				d.endColor(d.colors.FieldName)
+				if d.cs.ShowFieldTags && vtf.Tag != "" {
+					d.w.Write(spaceBytes)
+					d.w.Write(backquoteBytes)
+					d.w.Write([]byte(vtf.Tag))
+					d.w.Write(backquoteBytes)
 				}
+				d.colon()
+				d.ignoreNextIndent = true
+				d.dumpField(name, d.unpackValue(v.Field(fi)))
+				d.endEntry(i == numEntries-1)
+			}
+			if numShown < numEntries {
+				d.indent()
+				printMore(d.w, numEntries-numShown, moreFieldsBytes)
+				d.newline()
 			}
 		}
 		d.depth--
@@ -431,10 +1092,32 @@ func (d *dumpState) dump(v reflect.Value) {
 		d.w.Write(closeBraceBytes)
 
 	case reflect.Uintptr:
-		printHexPtr(d.w, uintptr(v.Uint()))
+		d.startColor(d.colors.Pointer)
+		printPtrOrNil(d.cs, d.w, uintptr(v.Uint()))
+		d.endColor(d.colors.Pointer)
+
+	case reflect.Func:
+		// Display the symbol name and source location of the function,
+		// when they're available, since a bare address is opaque.  Only the
+		// base name of the file is displayed so the output doesn't depend on
+		// where the program was built.
+		if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+			file, line := fn.FileLine(fn.Entry())
+			d.w.Write([]byte(fn.Name()))
+			d.w.Write(spaceBytes)
+			d.w.Write(openParenBytes)
+			d.w.Write([]byte(path.Base(file)))
+			d.w.Write(colonBytes)
+			printInt(d.w, int64(line), 10)
+			d.w.Write(closeParenBytes)
+			d.w.Write(spaceBytes)
+		}
+		fallthrough
 
-	case reflect.UnsafePointer, reflect.Chan, reflect.Func:
-		printHexPtr(d.w, v.Pointer())
+	case reflect.UnsafePointer, reflect.Chan:
+		d.startColor(d.colors.Pointer)
+		d.printPtr(v.Pointer())
+		d.endColor(d.colors.Pointer)
 
 	// There were not any other types at the time this code was written, but
 	// fall back to letting the default fmt package handle it in case any new
@@ -448,39 +1131,357 @@ func (d *dumpState) dump(v reflect.Value) {
 	}
 }
 
+// errWriter wraps an io.Writer to count the number of bytes written and stop
+// writing once the first error is encountered.  This allows the dump code to
+// write freely without checking every write while still reporting failures.
+type errWriter struct {
+	w   io.Writer
+	n   int
+	err error
+}
+
+// Write writes p to the underlying writer unless a previous write failed, in
+// which case the previous error is returned without writing anything.
+//
+// This implements the io.Writer interface.
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	ew.n += n
+	ew.err = err
+	return n, err
+}
+
+// budgetWriter wraps an io.Writer to limit the total number of bytes written
+// to it.  Anything written beyond the limit is discarded and marks the budget
+// as exhausted so dumping can stop early.
+type budgetWriter struct {
+	w         io.Writer
+	max       int
+	written   int
+	exhausted bool
+	endsLine  bool
+}
+
+// Write writes as much of p to the underlying writer as the remaining budget
+// allows.  The rest is discarded without error so callers can write freely.
+//
+// This implements the io.Writer interface.
+func (bw *budgetWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if remaining := bw.max - bw.written; n > remaining {
+		p = p[:remaining]
+		bw.exhausted = true
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+	bw.written += len(p)
+	bw.endsLine = p[len(p)-1] == '\n'
+	if _, err := bw.w.Write(p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// finish outputs the marker indicating the output was truncated, on its own
+// line, when the budget was exhausted.
+func (bw *budgetWriter) finish() {
+	if !bw.exhausted {
+		return
+	}
+	if !bw.endsLine {
+		bw.w.Write(newlineBytes)
+	}
+	bw.w.Write(outputTruncatedBytes)
+	printInt(bw.w, int64(bw.max), 10)
+	bw.w.Write(bytesCloseParenBytes)
+	bw.w.Write(newlineBytes)
+}
+
+// prefixWriter wraps an io.Writer to write a prefix at the start of every line
+// written to it.  The prefix for a line is only written once the first byte of
+// the line is, so output which ends with a newline isn't followed by a prefix.
+type prefixWriter struct {
+	w       io.Writer
+	prefix  []byte
+	midLine bool
+}
+
+// Write writes p to the underlying writer with the prefix inserted at the
+// start of each line.
+//
+// This implements the io.Writer interface.
+func (pw *prefixWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if !pw.midLine {
+			if _, err := pw.w.Write(pw.prefix); err != nil {
+				return 0, err
+			}
+			pw.midLine = true
+		}
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+			pw.midLine = false
+		}
+		if _, err := pw.w.Write(line); err != nil {
+			return 0, err
+		}
+		p = p[len(line):]
+	}
+	return n, nil
+}
+
+// flusher is implemented by writers, such as bufio.Writer, which buffer writes
+// until they are flushed.
+type flusher interface {
+	Flush() error
+}
+
+// isBuffered returns whether or not the passed writer already buffers writes
+// or is in memory such that there is no benefit to buffering writes to it.
+func isBuffered(w io.Writer) bool {
+	switch w.(type) {
+	case flusher, *bytes.Buffer:
+		return true
+	}
+	return false
+}
+
 // fdump is a helper function to consolidate the logic from the various public
-// methods which take varying writers and config states.
-func fdump(cs *ConfigState, w io.Writer, a ...interface{}) {
+// methods which take varying writers and config states.  It returns the number
+// of bytes written and the first write error encountered, if any.
+//
+// Dumping issues a large number of small writes, so they are buffered unless
+// the writer is already buffered in order to avoid the overhead of passing
+// each one to writers such as files and network connections.  Writers which
+// buffer on their own are not flushed since that is up to their owner.
+func fdump(cs *ConfigState, w io.Writer, a ...interface{}) (n int, err error) {
+	cs = cs.snapshot()
+	ew := &errWriter{w: w}
+	if isBuffered(w) {
+		dumpArgs(cs, ew, a...)
+		return ew.n, ew.err
+	}
+
+	bw := bufio.NewWriter(ew)
+	dumpArgs(cs, bw, a...)
+	bw.Flush()
+	return ew.n, ew.err
+}
+
+// dumpArg dumps the passed top-level argument.  Any panic which occurs while
+// traversing it, such as from a corrupted value or a Redact callback, is
+// recovered and displayed in place of the remainder of the argument so the
+// partial output is kept and the remaining arguments are still dumped.
+func (d *dumpState) dumpArg(arg interface{}) {
+	v := reflect.ValueOf(arg)
+	defer catchPanic(d.w, v)
+	d.dumpPath("", v)
+}
+
+// dumpArgs writes each of the passed arguments to Writer w according to the
+// passed config state.
+func dumpArgs(cs *ConfigState, w io.Writer, a ...interface{}) {
+	if cs.LinePrefix != "" {
+		w = &prefixWriter{w: w, prefix: []byte(cs.LinePrefix)}
+	}
+
+	// Output beyond the maximum size is discarded when one is requested.
+	var budget *budgetWriter
+	if cs.MaxOutputBytes > 0 {
+		budget = &budgetWriter{w: w, max: cs.MaxOutputBytes}
+		defer budget.finish()
+		w = budget
+	}
+
+	// Methods in progress are tracked across all of the passed arguments and
+	// shared with the dump this one was started by, if any.
+	if cs.methods == nil {
+		cs.methods = make(map[methodCall]bool)
+	}
+
+	if cs.JSON {
+		fjdump(cs, w, a...)
+		return
+	}
+	if cs.GoSyntax {
+		fgodump(cs, w, a...)
+		return
+	}
+
+	// Pointers which have already been dumped are tracked across all of the
+	// passed arguments when deduplication is requested.
+	var dumped map[uintptr]bool
+	if cs.DedupPointers {
+		dumped = make(map[uintptr]bool)
+	}
+
+	// Likewise, ids assigned to pointers are consistent across all of the
+	// passed arguments when aliases are requested.
+	var aliases map[uintptr]int
+	if cs.PointerAliases {
+		aliases = make(map[uintptr]int)
+	}
+
+	// Colors are only used when enabled.  Leaving them empty otherwise
+	// ensures no escape sequences are written.
+	var colors Colors
+	if cs.EnableColors {
+		colors = DefaultColors
+		if cs.Colors != nil {
+			colors = *cs.Colors
+		}
+	}
+
 	for _, arg := range a {
+		if budget != nil && budget.exhausted {
+			break
+		}
+
+		d := dumpState{w: w, cs: cs, dumped: dumped, aliases: aliases,
+			budget: budget, colors: colors}
 		if arg == nil {
-			w.Write(interfaceBytes)
-			w.Write(spaceBytes)
-			w.Write(nilAngleBytes)
-			w.Write(newlineBytes)
+			d.w.Write(openParenBytes)
+			d.startColor(colors.Type)
+			d.w.Write(interfaceTypeBytes)
+			d.endColor(colors.Type)
+			d.w.Write(closeParenBytes)
+			d.w.Write(spaceBytes)
+			d.startColor(colors.Nil)
+			d.w.Write(d.cs.nilMarker())
+			d.endColor(colors.Nil)
+			d.w.Write(newlineBytes)
 			continue
 		}
 
-		d := dumpState{w: w, cs: cs}
-		d.pointers = make(map[uintptr]int)
-		d.dump(reflect.ValueOf(arg))
+		d.pointers = pointersPool.Get().(map[uintptr]int)
+		d.dumpArg(arg)
 		d.w.Write(newlineBytes)
+		for k := range d.pointers {
+			delete(d.pointers, k)
+		}
+		pointersPool.Put(d.pointers)
 	}
 }
 
 // Fdump formats and displays the passed arguments to io.Writer w.  It formats
-// exactly the same as Dump.
-func Fdump(w io.Writer, a ...interface{}) {
-	fdump(&Config, w, a...)
+// exactly the same as Dump.  It returns the number of bytes written and the
+// first write error encountered, after which nothing more is written.
+func Fdump(w io.Writer, a ...interface{}) (n int, err error) {
+	return DumpTo(w, &Config, a...)
+}
+
+// DumpTo formats and displays the passed arguments to io.Writer w according to
+// the passed config state rather than the global Config, which is used when
+// cfg is nil.  It returns the number of bytes written and the first write
+// error encountered, after which nothing more is written.
+func DumpTo(w io.Writer, cfg *ConfigState, a ...interface{}) (n int, err error) {
+	if cfg == nil {
+		cfg = &Config
+	}
+	return fdump(cfg, w, a...)
+}
+
+// fdumpAll is a helper function to consolidate the logic from the various
+// public methods which dump to multiple writers.  The arguments are only
+// dumped once, into a buffer, and the finished output is then written to each
+// writer in a single write.  Every writer is written to even when writing to
+// an earlier one fails, and the first error encountered is returned.
+func fdumpAll(cs *ConfigState, writers []io.Writer, a ...interface{}) error {
+	var buf bytes.Buffer
+	fdump(cs, &buf, a...)
+
+	var firstErr error
+	for _, w := range writers {
+		if _, err := w.Write(buf.Bytes()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// FdumpAll formats and displays the passed arguments to each of the passed
+// writers exactly the same as Fdump.  Unlike dumping to an io.MultiWriter, the
+// arguments are only dumped once and each writer receives the finished output
+// in a single write, which avoids multiplying the overhead of the many small
+// writes dumping issues as well as partial output when a writer fails midway.
+// It returns the first write error encountered, if any.
+func FdumpAll(writers []io.Writer, a ...interface{}) error {
+	return fdumpAll(&Config, writers, a...)
 }
 
 // Sdump returns a string with the passed arguments formatted exactly the same
 // as Dump.
 func Sdump(a ...interface{}) string {
 	var buf bytes.Buffer
-	fdump(&Config, &buf, a...)
+	DumpTo(&buf, &Config, a...)
 	return buf.String()
 }
 
+// Dumpb returns a byte slice with the passed arguments formatted exactly the
+// same as Dump.  It avoids the copy made when converting the result of Sdump
+// when the output is needed as bytes, such as for hashing it.
+func Dumpb(a ...interface{}) []byte {
+	var buf bytes.Buffer
+	DumpTo(&buf, &Config, a...)
+	return buf.Bytes()
+}
+
+// DumpSize returns the number of bytes Dump would output for the passed
+// arguments without keeping the output.  The arguments are traversed exactly
+// the same as by Dump, so the size is exact, but only the count is kept in
+// memory.  This allows checking whether or not a value is safe to dump, such
+// as to a log, before doing so.
+func DumpSize(a ...interface{}) int {
+	n, _ := fdump(&Config, ioutil.Discard, a...)
+	return n
+}
+
+// FdumpMaxDepth formats and displays the passed arguments to io.Writer w
+// exactly the same as Fdump except that nested data structures are only
+// descended into depth levels deep regardless of the MaxDepth option of the
+// global Config.  The global Config is not modified, so this is safe to use
+// concurrently with other calls.
+func FdumpMaxDepth(w io.Writer, depth int, a ...interface{}) (n int, err error) {
+	cs := Config
+	cs.MaxDepth = depth
+	return fdump(&cs, w, a...)
+}
+
+// SdumpMaxDepth returns a string with the passed arguments formatted exactly
+// the same as FdumpMaxDepth.
+func SdumpMaxDepth(depth int, a ...interface{}) string {
+	var buf bytes.Buffer
+	FdumpMaxDepth(&buf, depth, a...)
+	return buf.String()
+}
+
+// NormalizeAddresses returns the passed output, such as a dump captured from a
+// log, with each distinct pointer address replaced by a stable placeholder so
+// output from different runs can be compared.  Addresses are numbered in the
+// order they first appear, in the form 0xADDR1, 0xADDR2, and so on, and every
+// occurrence of the same address, including those in pointer chains, is
+// replaced by the same placeholder.  Note that any other lowercase hexadecimal
+// numbers with a 0x prefix, such as integers displayed with an IntBase of 16,
+// are indistinguishable from addresses and are replaced as well.  See the
+// PointerAliases option to produce stable output in the first place.
+func NormalizeAddresses(dump string) string {
+	ids := make(map[string]string)
+	return addressRE.ReplaceAllStringFunc(dump, func(addr string) string {
+		id, ok := ids[addr]
+		if !ok {
+			id = "0xADDR" + strconv.Itoa(len(ids)+1)
+			ids[addr] = id
+		}
+		return id
+	})
+}
+
 /*
 Dump displays the passed parameters to standard out with newlines, customizable
 indentation, and additional debug information such as complete types and all
@@ -505,5 +1506,5 @@ See Fdump if you would prefer dumping to an arbitrary io.Writer or Sdump to
 get the formatted result as a string.
 */
 func Dump(a ...interface{}) {
-	fdump(&Config, os.Stdout, a...)
+	DumpTo(os.Stdout, &Config, a...)
 }
@@ -62,8 +62,16 @@ base test element are also tested to ensure proper indirection across all types.
 package spew_test
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 	"unsafe"
 
@@ -306,13 +314,12 @@ func addArrayDumpTests() {
 	// Array containing standard ints.
 	v := [3]int{1, 2, 3}
 	vLen := fmt.Sprintf("%d", len(v))
-	vCap := fmt.Sprintf("%d", cap(v))
 	nv := (*[3]int)(nil)
 	pv := &v
 	vAddr := fmt.Sprintf("%p", pv)
 	pvAddr := fmt.Sprintf("%p", &pv)
 	vt := "int"
-	vs := "(len=" + vLen + " cap=" + vCap + ") {\n (" + vt + ") 1,\n (" +
+	vs := "(len=" + vLen + ") {\n (" + vt + ") 1,\n (" +
 		vt + ") 2,\n (" + vt + ") 3\n}"
 	addDumpTest(v, "([3]"+vt+") "+vs+"\n")
 	addDumpTest(pv, "(*[3]"+vt+")("+vAddr+")("+vs+")\n")
@@ -328,19 +335,18 @@ func addArrayDumpTests() {
 	v2i1Len := fmt.Sprintf("%d", len(v2i1))
 	v2i2Len := fmt.Sprintf("%d", len(v2i2))
 	v2Len := fmt.Sprintf("%d", len(v2))
-	v2Cap := fmt.Sprintf("%d", cap(v2))
 	nv2 := (*[3]pstringer)(nil)
 	pv2 := &v2
 	v2Addr := fmt.Sprintf("%p", pv2)
 	pv2Addr := fmt.Sprintf("%p", &pv2)
 	v2t := "spew_test.pstringer"
-	v2sp := "(len=" + v2Len + " cap=" + v2Cap + ") {\n (" + v2t +
+	v2sp := "(len=" + v2Len + ") {\n (" + v2t +
 		") (len=" + v2i0Len + ") stringer 1,\n (" + v2t +
 		") (len=" + v2i1Len + ") stringer 2,\n (" + v2t +
 		") (len=" + v2i2Len + ") " + "stringer 3\n}"
 	v2s := v2sp
 	if spew.UnsafeDisabled {
-		v2s = "(len=" + v2Len + " cap=" + v2Cap + ") {\n (" + v2t +
+		v2s = "(len=" + v2Len + ") {\n (" + v2t +
 			") (len=" + v2i0Len + ") \"1\",\n (" + v2t + ") (len=" +
 			v2i1Len + ") \"2\",\n (" + v2t + ") (len=" + v2i2Len +
 			") " + "\"3\"\n}"
@@ -355,7 +361,6 @@ func addArrayDumpTests() {
 	v3 := [3]interface{}{v3i0, int(2), uint(3)}
 	v3i0Len := fmt.Sprintf("%d", len(v3i0))
 	v3Len := fmt.Sprintf("%d", len(v3))
-	v3Cap := fmt.Sprintf("%d", cap(v3))
 	nv3 := (*[3]interface{})(nil)
 	pv3 := &v3
 	v3Addr := fmt.Sprintf("%p", pv3)
@@ -364,7 +369,7 @@ func addArrayDumpTests() {
 	v3t2 := "string"
 	v3t3 := "int"
 	v3t4 := "uint"
-	v3s := "(len=" + v3Len + " cap=" + v3Cap + ") {\n (" + v3t2 + ") " +
+	v3s := "(len=" + v3Len + ") {\n (" + v3t2 + ") " +
 		"(len=" + v3i0Len + ") \"one\",\n (" + v3t3 + ") 2,\n (" +
 		v3t4 + ") 3\n}"
 	addDumpTest(v3, "("+v3t+") "+v3s+"\n")
@@ -381,13 +386,12 @@ func addArrayDumpTests() {
 		0x31, 0x32,
 	}
 	v4Len := fmt.Sprintf("%d", len(v4))
-	v4Cap := fmt.Sprintf("%d", cap(v4))
 	nv4 := (*[34]byte)(nil)
 	pv4 := &v4
 	v4Addr := fmt.Sprintf("%p", pv4)
 	pv4Addr := fmt.Sprintf("%p", &pv4)
 	v4t := "[34]uint8"
-	v4s := "(len=" + v4Len + " cap=" + v4Cap + ") " +
+	v4s := "(len=" + v4Len + ") " +
 		"{\n 00000000  11 12 13 14 15 16 17 18  19 1a 1b 1c 1d 1e 1f 20" +
 		"  |............... |\n" +
 		" 00000010  21 22 23 24 25 26 27 28  29 2a 2b 2c 2d 2e 2f 30" +
@@ -818,6 +822,32 @@ func addChanDumpTests() {
 	addDumpTest(v2, "("+v2t+") "+v2s+"\n")
 	addDumpTest(pv2, "(*"+v2t+")("+v2Addr+")("+v2s+")\n")
 	addDumpTest(&pv2, "(**"+v2t+")("+pv2Addr+"->"+v2Addr+")("+v2s+")\n")
+
+	// Buffered channel with queued values.
+	v3 := make(chan int, 10)
+	v3 <- 1
+	v3 <- 2
+	v3 <- 3
+	pv3 := &v3
+	v3Addr := fmt.Sprintf("%p", pv3)
+	v3t := "chan int"
+	v3s := fmt.Sprintf("(len=3 cap=10) %p", v3)
+	addDumpTest(v3, "("+v3t+") "+v3s+"\n")
+	addDumpTest(pv3, "(*"+v3t+")("+v3Addr+")("+v3s+")\n")
+
+	// Empty buffered channel.
+	v4 := make(chan int, 4)
+	v4t := "chan int"
+	v4s := fmt.Sprintf("(cap=4) %p", v4)
+	addDumpTest(v4, "("+v4t+") "+v4s+"\n")
+}
+
+// funcDesc returns the symbol name, source location, and address the Dump
+// methods display for the passed function.
+func funcDesc(fn interface{}) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	_, line := f.FileLine(f.Entry())
+	return fmt.Sprintf("%s (dump_test.go:%d) %p", f.Name(), line, fn)
 }
 
 func addFuncDumpTests() {
@@ -828,7 +858,7 @@ func addFuncDumpTests() {
 	vAddr := fmt.Sprintf("%p", pv)
 	pvAddr := fmt.Sprintf("%p", &pv)
 	vt := "func()"
-	vs := fmt.Sprintf("%p", v)
+	vs := funcDesc(v)
 	addDumpTest(v, "("+vt+") "+vs+"\n")
 	addDumpTest(pv, "(*"+vt+")("+vAddr+")("+vs+")\n")
 	addDumpTest(&pv, "(**"+vt+")("+pvAddr+"->"+vAddr+")("+vs+")\n")
@@ -841,7 +871,7 @@ func addFuncDumpTests() {
 	v2Addr := fmt.Sprintf("%p", pv2)
 	pv2Addr := fmt.Sprintf("%p", &pv2)
 	v2t := "func(*testing.T)"
-	v2s := fmt.Sprintf("%p", v2)
+	v2s := funcDesc(v2)
 	addDumpTest(v2, "("+v2t+") "+v2s+"\n")
 	addDumpTest(pv2, "(*"+v2t+")("+v2Addr+")("+v2s+")\n")
 	addDumpTest(&pv2, "(**"+v2t+")("+pv2Addr+"->"+v2Addr+")("+v2s+")\n")
@@ -856,7 +886,7 @@ func addFuncDumpTests() {
 	v3Addr := fmt.Sprintf("%p", pv3)
 	pv3Addr := fmt.Sprintf("%p", &pv3)
 	v3t := "func(int, string) (bool, error)"
-	v3s := fmt.Sprintf("%p", v3)
+	v3s := funcDesc(v3)
 	addDumpTest(v3, "("+v3t+") "+v3s+"\n")
 	addDumpTest(pv3, "(*"+v3t+")("+v3Addr+")("+v3s+")\n")
 	addDumpTest(&pv3, "(**"+v3t+")("+pv3Addr+"->"+v3Addr+")("+v3s+")\n")
@@ -924,6 +954,30 @@ func addCircularDumpTests() {
 	addDumpTest(v3, "("+v3t+") "+v3s+"\n")
 	addDumpTest(pv3, "(*"+v3t+")("+v3Addr+")("+v3s2+")\n")
 	addDumpTest(&pv3, "(**"+v3t+")("+pv3Addr+"->"+v3Addr+")("+v3s2+")\n")
+
+	// Slice that contains itself.
+	v4 := make([]interface{}, 1)
+	v4[0] = v4
+	v4t := "[]interface {}"
+	v4s := "(len=1 cap=1) {\n (" + v4t + ") (len=1 cap=1) <already shown>\n}"
+	addDumpTest(v4, "("+v4t+") "+v4s+"\n")
+
+	// Map that contains itself.
+	v5 := map[string]interface{}{}
+	v5["self"] = v5
+	v5t := "map[string]interface {}"
+	v5s := "(len=1) {\n (string) (len=4) \"self\": (" + v5t +
+		") (len=1) <already shown>\n}"
+	addDumpTest(v5, "("+v5t+") "+v5s+"\n")
+
+	// Slices that share data without being circular.
+	v6s := []int{1}
+	v6 := []interface{}{v6s, [][]int{v6s}}
+	v6t := "[]interface {}"
+	v6s2 := "(len=2 cap=2) {\n ([]int) (len=1 cap=1) {\n  (int) 1\n },\n" +
+		" ([][]int) (len=1 cap=1) {\n  ([]int) (len=1 cap=1) {\n" +
+		"   (int) 1\n  }\n }\n}"
+	addDumpTest(v6, "("+v6t+") "+v6s2+"\n")
 }
 
 func addPanicDumpTests() {
@@ -956,6 +1010,53 @@ func addErrorDumpTests() {
 	addDumpTest(nv, "(*"+vt+")(<nil>)\n")
 }
 
+func addDumperDumpTests() {
+	// Type that has a custom Dumper interface.
+	v := ringDumper{Items: make([]int, 8), Head: 3}
+	nv := (*ringDumper)(nil)
+	pv := &v
+	vAddr := fmt.Sprintf("%p", pv)
+	pvAddr := fmt.Sprintf("%p", &pv)
+	vt := "spew_test.ringDumper"
+	vs := "ring of 8, head 3"
+	addDumpTest(v, "("+vt+") "+vs+"\n")
+	addDumpTest(pv, "(*"+vt+")("+vAddr+")("+vs+")\n")
+	addDumpTest(&pv, "(**"+vt+")("+pvAddr+"->"+vAddr+")("+vs+")\n")
+	addDumpTest(nv, "(*"+vt+")(<nil>)\n")
+
+	// Dumper nested in a struct.
+	v2 := struct{ R ringDumper }{R: v}
+	v2t := "struct { R spew_test.ringDumper }"
+	v2s := "{\n R: (" + vt + ") " + vs + "\n}"
+	addDumpTest(v2, "("+v2t+") "+v2s+"\n")
+}
+
+func addReflectValueDumpTests() {
+	// reflect.Value which represents an int.
+	v := reflect.ValueOf(127)
+	pv := &v
+	vAddr := fmt.Sprintf("%p", pv)
+	vt := "reflect.Value"
+	vs := "(int) 127"
+	addDumpTest(v, vs+"\n")
+	addDumpTest(pv, "(*"+vt+")("+vAddr+")("+vs+")\n")
+
+	// Zero reflect.Value.
+	addDumpTest(reflect.Value{}, "<invalid>\n")
+
+	// Struct with reflect.Value fields.
+	v2 := struct{ A, B reflect.Value }{A: reflect.ValueOf("hi")}
+	v2t := "struct { A reflect.Value; B reflect.Value }"
+	v2s := "{\n A: (string) (len=2) \"hi\",\n B: <invalid>\n}"
+	addDumpTest(v2, "("+v2t+") "+v2s+"\n")
+
+	// Slice of reflect.Values.
+	v3 := []reflect.Value{reflect.ValueOf(1.5)}
+	v3t := "[]reflect.Value"
+	v3s := "(len=1 cap=1) {\n (float64) 1.5\n}"
+	addDumpTest(v3, "("+v3t+") "+v3s+"\n")
+}
+
 // TestDump executes all of the tests described by dumpTests.
 func TestDump(t *testing.T) {
 	// Setup tests.
@@ -977,6 +1078,8 @@ func TestDump(t *testing.T) {
 	addCircularDumpTests()
 	addPanicDumpTests()
 	addErrorDumpTests()
+	addDumperDumpTests()
+	addReflectValueDumpTests()
 	addCgoDumpTests()
 
 	t.Logf("Running %d tests", len(dumpTests))
@@ -1039,4 +1142,570 @@ func TestDumpSortedKeys(t *testing.T) {
 		t.Errorf("Sorted keys mismatch:\n  %v %v", s, expected)
 	}
 
+	s = cfg.Sdump(map[complex128]int{2 + 1i: 3, 1 + 2i: 1, 2 - 1i: 2})
+	expected = "(map[complex128]int) (len=3) {\n" +
+		"(complex128) (1+2i): (int) 1,\n" +
+		"(complex128) (2-1i): (int) 2,\n" +
+		"(complex128) (2+1i): (int) 3\n" +
+		"}\n"
+	if s != expected {
+		t.Errorf("Sorted keys mismatch:\n  %v %v", s, expected)
+	}
+
+	s = cfg.Sdump(map[[2]int]string{{2, 1}: "c", {1, 2}: "b", {1, 1}: "a"})
+	expected = "(map[[2]int]string) (len=3) {\n" +
+		"([2]int) (len=2) {\n(int) 1,\n(int) 1\n}: (string) (len=1) \"a\",\n" +
+		"([2]int) (len=2) {\n(int) 1,\n(int) 2\n}: (string) (len=1) \"b\",\n" +
+		"([2]int) (len=2) {\n(int) 2,\n(int) 1\n}: (string) (len=1) \"c\"\n" +
+		"}\n"
+	if s != expected {
+		t.Errorf("Sorted keys mismatch:\n  %v %v", s, expected)
+	}
+
+	one, two, three := "1", "2", "3"
+	cfg.DisablePointerAddresses = true
+	s = cfg.Sdump(map[*string]int{&three: 3, &one: 1, &two: 2})
+	expected = "(map[*string]int) (len=3) {\n" +
+		"(*string)((len=1) \"1\"): (int) 1,\n" +
+		"(*string)((len=1) \"2\"): (int) 2,\n" +
+		"(*string)((len=1) \"3\"): (int) 3\n" +
+		"}\n"
+	if s != expected {
+		t.Errorf("Sorted keys mismatch:\n  %v %v", s, expected)
+	}
+
+}
+
+// limitedWriter is an io.Writer that fails once more than max bytes have been
+// written to it.  It also tracks any writes attempted after it failed.
+type limitedWriter struct {
+	buf         bytes.Buffer
+	max         int
+	failed      bool
+	extraWrites int
+}
+
+// errLimitReached is the error returned by limitedWriter once its limit is
+// exceeded.
+var errLimitReached = errors.New("write limit reached")
+
+// Write implements the io.Writer interface.
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.failed {
+		w.extraWrites++
+		return 0, errLimitReached
+	}
+	if w.buf.Len()+len(p) > w.max {
+		n := w.max - w.buf.Len()
+		w.buf.Write(p[:n])
+		w.failed = true
+		return n, errLimitReached
+	}
+	return w.buf.Write(p)
+}
+
+// configChanger is a Stringer which modifies the config state it was created
+// for when invoked in order to test that changes made during a dump don't
+// affect it.
+type configChanger struct {
+	cs *spew.ConfigState
+}
+
+// String implements the Stringer interface.
+func (c configChanger) String() string {
+	c.cs.Indent = "XX"
+	return "changed"
+}
+
+// TestDumpConfigSnapshot ensures changes made to the config state while a dump
+// is in progress do not affect it.
+func TestDumpConfigSnapshot(t *testing.T) {
+	cs := &spew.ConfigState{Indent: " "}
+	v := []interface{}{configChanger{cs}, 1}
+	s := cs.Sdump(v)
+	expected := "([]interface {}) (len=2 cap=2) {\n" +
+		" (spew_test.configChanger) changed,\n" +
+		" (int) 1\n" +
+		"}\n"
+	if s != expected {
+		t.Errorf("Config snapshot mismatch:\n  %v %v", s, expected)
+	}
+	if cs.Indent != "XX" {
+		t.Errorf("Config was not changed: %q", cs.Indent)
+	}
+}
+
+// TestDumpPanicRecovery ensures a panic while dumping an argument is displayed
+// in place of the remainder of it and the other arguments are still dumped.
+func TestDumpPanicRecovery(t *testing.T) {
+	cs := &spew.ConfigState{Indent: " "}
+	cs.Redact = func(path string, v reflect.Value) (string, bool) {
+		if path == ".B" {
+			panic("test panic")
+		}
+		return "", false
+	}
+	s := cs.Sdump(struct{ A, B int }{1, 2}, 3)
+	expected := "(struct { A int; B int }) {\n" +
+		" A: (int) 1,\n" +
+		" B: (PANIC=test panic)\n" +
+		"(int) 3\n"
+	if s != expected {
+		t.Errorf("Panic recovery mismatch:\n  %v %v", s, expected)
+	}
+}
+
+// panicWriter is an io.Writer which panics when it is passed the trigger
+// string in order to test recovering from panics while dumping.
+type panicWriter struct {
+	bytes.Buffer
+	trigger string
+}
+
+func (w *panicWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), w.trigger) {
+		panic("test panic")
+	}
+	return w.Buffer.Write(p)
+}
+
+// Flush implements the flusher interface so the writes aren't buffered.
+func (w *panicWriter) Flush() error {
+	return nil
+}
+
+// TestDumpPanicRecoveryJSON ensures a panic while dumping an argument as JSON
+// is displayed as a JSON string in place of it, so the output remains valid
+// JSON, and the other arguments are still dumped.
+func TestDumpPanicRecoveryJSON(t *testing.T) {
+	cs := &spew.ConfigState{JSON: true}
+	cs.Redact = func(path string, v reflect.Value) (string, bool) {
+		if path == ".B" {
+			panic("test panic")
+		}
+		return "", false
+	}
+	s := cs.Sdump(struct{ A, B int }{1, 2}, 3)
+	expected := "\"(PANIC=test panic)\"\n3\n"
+	if s != expected {
+		t.Errorf("Panic recovery mismatch:\n  %q %q", s, expected)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("Panic recovery output is not valid JSON: %q", line)
+		}
+	}
+}
+
+// TestDumpPanicRecoveryGoSyntax ensures a panic while dumping an argument as Go
+// syntax is displayed in place of the remainder of it and the other arguments
+// are still dumped.
+func TestDumpPanicRecoveryGoSyntax(t *testing.T) {
+	cs := &spew.ConfigState{Indent: " ", GoSyntax: true}
+	w := &panicWriter{trigger: "bad"}
+	cs.Fdump(w, struct{ A, B string }{"ok", "bad"}, 3)
+	expected := "struct { A string; B string }{\n A: \"ok\",\n B: (PANIC=test panic)\n3\n"
+	if s := w.String(); s != expected {
+		t.Errorf("Panic recovery mismatch:\n  %q %q", s, expected)
+	}
+}
+
+// deepList is a linked list node used to test pathologically deep structures.
+type deepList struct {
+	Next *deepList
+}
+
+// TestDumpDeepStructure ensures structures which are nested far deeper than the
+// stack allows to recurse into are cut off instead of crashing.
+func TestDumpDeepStructure(t *testing.T) {
+	var head *deepList
+	for i := 0; i < 50000; i++ {
+		head = &deepList{Next: head}
+	}
+
+	cs := spew.ConfigState{Compact: true, DisablePointerAddresses: true}
+	s := cs.Sdump(head)
+	if !strings.Contains(s, "<max depth reached>") {
+		t.Errorf("Deep structure was not cut off: %d bytes", len(s))
+	}
+	s = cs.Sprintf("%v", head)
+	if !strings.Contains(s, "<max>") {
+		t.Errorf("Deep structure was not cut off: %d bytes", len(s))
+	}
+}
+
+// TestDumpMaxOutputBytes ensures the MaxOutputBytes limit applies to all of the
+// passed arguments combined and the truncation marker is on its own line.
+func TestDumpMaxOutputBytes(t *testing.T) {
+	cs := spew.ConfigState{Indent: " ", MaxOutputBytes: 22}
+	s := cs.Sdump("ab", "cd")
+	expected := "(string) (len=2) \"ab\"\n" +
+		"... (output truncated at 22 bytes)\n"
+	if s != expected {
+		t.Errorf("MaxOutputBytes mismatch:\n  %q %q", s, expected)
+	}
+}
+
+// writeCounter is an io.Writer that counts the number of writes made to it.
+type writeCounter struct {
+	buf    bytes.Buffer
+	writes int
+}
+
+// Write implements the io.Writer interface.
+func (w *writeCounter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.buf.Write(p)
+}
+
+// TestFdumpBuffering ensures Fdump buffers its writes to unbuffered writers
+// without changing the output and leaves flushing buffered writers to their
+// owner.
+func TestFdumpBuffering(t *testing.T) {
+	v := struct {
+		A []int
+		B map[string]int
+	}{[]int{1, 2}, map[string]int{"one": 1}}
+	want := spew.Sdump(v)
+
+	w := &writeCounter{}
+	spew.Fdump(w, v)
+	if w.buf.String() != want {
+		t.Errorf("Fdump: got %q, want %q", w.buf.String(), want)
+	}
+	if w.writes != 1 {
+		t.Errorf("Fdump: got %d writes, want 1", w.writes)
+	}
+
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	spew.Fdump(bw, v)
+	if buf.Len() != 0 {
+		t.Errorf("Fdump: flushed buffered writer: %q", buf.String())
+	}
+	bw.Flush()
+	if buf.String() != want {
+		t.Errorf("Fdump: got %q, want %q", buf.String(), want)
+	}
+}
+
+// benchInner and benchOuter make up the moderately nested struct used by the
+// dump benchmarks.
+type benchInner struct {
+	Name  string
+	Tags  []string
+	Score float64
+}
+
+type benchOuter struct {
+	ID       int
+	Children []*benchInner
+	Lookup   map[string]benchInner
+}
+
+// newBenchOuter returns the value dumped by the dump benchmarks.
+func newBenchOuter() benchOuter {
+	v := benchOuter{ID: 1, Lookup: map[string]benchInner{"a": {Name: "a"}}}
+	for i := 0; i < 10; i++ {
+		v.Children = append(v.Children, &benchInner{Name: "child",
+			Tags: []string{"x", "y"}, Score: float64(i)})
+	}
+	return v
+}
+
+// BenchmarkFdump measures dumping a moderately nested struct to a writer.
+func BenchmarkFdump(b *testing.B) {
+	v := newBenchOuter()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		spew.Fdump(ioutil.Discard, v)
+	}
+}
+
+// BenchmarkFdumpBytes measures hexdumping a nested byte slice to a writer.
+func BenchmarkFdumpBytes(b *testing.B) {
+	v := struct{ Data []byte }{make([]byte, 1024)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		spew.Fdump(ioutil.Discard, v)
+	}
+}
+
+// BenchmarkSdump measures dumping a moderately nested struct to a string.
+func BenchmarkSdump(b *testing.B) {
+	v := newBenchOuter()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		spew.Sdump(v)
+	}
+}
+
+// TestDumpMaxDepth ensures the depth passed to FdumpMaxDepth and SdumpMaxDepth
+// limits the output without modifying the global Config.
+func TestDumpMaxDepth(t *testing.T) {
+	v := [][]int{{1}}
+	want := "([][]int) (len=1 cap=1) {\n" +
+		" ([]int) (len=1 cap=1) {\n  <max depth reached>\n }\n}\n"
+
+	s := spew.SdumpMaxDepth(1, v)
+	if s != want {
+		t.Errorf("SdumpMaxDepth mismatch:\n  %v %v", s, want)
+	}
+
+	var buf bytes.Buffer
+	n, err := spew.FdumpMaxDepth(&buf, 1, v)
+	if err != nil || n != len(want) || buf.String() != want {
+		t.Errorf("FdumpMaxDepth: got %d bytes %q (err %v), want %d bytes %q",
+			n, buf.String(), err, len(want), want)
+	}
+
+	if spew.Config.MaxDepth != 0 {
+		t.Errorf("FdumpMaxDepth modified Config.MaxDepth: %d",
+			spew.Config.MaxDepth)
+	}
+	if s := spew.Sdump(v); s == want {
+		t.Errorf("Sdump unexpectedly limited by depth:\n  %v", s)
+	}
+}
+
+// TestFdumpResult ensures Fdump returns the number of bytes written and stops
+// writing after the first write error.
+func TestFdumpResult(t *testing.T) {
+	v := []int{1, 2, 3}
+	want := spew.Sdump(v)
+
+	var buf bytes.Buffer
+	n, err := spew.Fdump(&buf, v)
+	if err != nil {
+		t.Errorf("Fdump: unexpected error %v", err)
+	}
+	if n != len(want) || buf.String() != want {
+		t.Errorf("Fdump: got %d bytes %q, want %d bytes %q", n, buf.String(),
+			len(want), want)
+	}
+
+	w := &limitedWriter{max: 10}
+	n, err = spew.Fdump(w, v)
+	if err != errLimitReached {
+		t.Errorf("Fdump: got error %v, want %v", err, errLimitReached)
+	}
+	if n != 10 || w.buf.String() != want[:10] {
+		t.Errorf("Fdump: got %d bytes %q, want 10 bytes %q", n, w.buf.String(),
+			want[:10])
+	}
+	if w.extraWrites != 0 {
+		t.Errorf("Fdump: got %d writes after the error, want 0",
+			w.extraWrites)
+	}
+}
+
+// TestDumpTo ensures DumpTo honors the passed config state and falls back to
+// the global Config when it is nil.
+func TestDumpTo(t *testing.T) {
+	v := map[string]int{"b": 2, "a": 1}
+	cs := &spew.ConfigState{Indent: "\t", SortKeys: true}
+	want := cs.Sdump(v)
+
+	var buf bytes.Buffer
+	n, err := spew.DumpTo(&buf, cs, v)
+	if err != nil || n != len(want) || buf.String() != want {
+		t.Errorf("DumpTo: got %d bytes %q (err %v), want %d bytes %q",
+			n, buf.String(), err, len(want), want)
+	}
+
+	buf.Reset()
+	want = spew.Sdump(1)
+	n, err = spew.DumpTo(&buf, nil, 1)
+	if err != nil || n != len(want) || buf.String() != want {
+		t.Errorf("DumpTo nil config: got %d bytes %q (err %v), want %d "+
+			"bytes %q", n, buf.String(), err, len(want), want)
+	}
+}
+
+// TestDumpMaxDepthLinkedList ensures MaxDepth bounds the output of a long
+// chain of pointers to structs, such as a linked list, for both Dump and the
+// Formatter.
+func TestDumpMaxDepthLinkedList(t *testing.T) {
+	type node struct {
+		V    int
+		Next *node
+	}
+	var head *node
+	for i := 999; i >= 0; i-- {
+		head = &node{i, head}
+	}
+
+	cs := spew.ConfigState{Indent: " ", MaxDepth: 5,
+		DisablePointerAddresses: true}
+	want := "(*spew_test.node)({\n" +
+		" V: (int) 0,\n" +
+		" Next: (*spew_test.node)({\n" +
+		"  V: (int) 1,\n" +
+		"  Next: (*spew_test.node)({\n" +
+		"   V: (int) 2,\n" +
+		"   Next: (*spew_test.node)({\n" +
+		"    V: (int) 3,\n" +
+		"    Next: (*spew_test.node)({\n" +
+		"     V: (int) 4,\n" +
+		"     Next: (*spew_test.node)({\n" +
+		"      <max depth reached>\n" +
+		"     })\n    })\n   })\n  })\n })\n})\n"
+	if s := cs.Sdump(head); s != want {
+		t.Errorf("Sdump mismatch:\n  got: %s\n want: %s", s, want)
+	}
+
+	want = "<*>{0 <*>{1 <*>{2 <*>{3 <*>{4 <*>{<max>}}}}}}"
+	if s := cs.Sprintf("%v", head); s != want {
+		t.Errorf("Sprintf mismatch:\n  got: %s\n want: %s", s, want)
+	}
+}
+
+// TestFdumpAll ensures FdumpAll writes the full output to every writer in a
+// single write and returns the first write error.
+func TestFdumpAll(t *testing.T) {
+	v := []int{1, 2, 3}
+	cs := &spew.ConfigState{Indent: "\t"}
+	want := cs.Sdump(v)
+
+	var buf1, buf2 bytes.Buffer
+	failing := &limitedWriter{max: 5}
+	err := cs.FdumpAll([]io.Writer{&buf1, failing, &buf2}, v)
+	if err != errLimitReached {
+		t.Errorf("FdumpAll: got error %v, want %v", err, errLimitReached)
+	}
+	if buf1.String() != want || buf2.String() != want {
+		t.Errorf("FdumpAll: got %q and %q, want %q", buf1.String(),
+			buf2.String(), want)
+	}
+	if failing.extraWrites != 0 {
+		t.Errorf("FdumpAll: got %d writes after the error, want 0",
+			failing.extraWrites)
+	}
+
+	buf1.Reset()
+	if err := spew.FdumpAll([]io.Writer{&buf1}, v); err != nil {
+		t.Errorf("FdumpAll: unexpected error %v", err)
+	}
+	if want := spew.Sdump(v); buf1.String() != want {
+		t.Errorf("FdumpAll: got %q, want %q", buf1.String(), want)
+	}
+}
+
+// TestNormalizeAddresses ensures NormalizeAddresses replaces each distinct
+// address with the same placeholder every time it appears.
+func TestNormalizeAddresses(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"(int) 5\n", "(int) 5\n"},
+		{"(**int)(0xc000012345->0xc0000abcde)(5)\n",
+			"(**int)(0xADDR1->0xADDR2)(5)\n"},
+		{"(*main.n)(0xc00001)({\n Next: (*main.n)(0xc00002)({\n" +
+			"  Next: (*main.n)(0xc00001)(<already shown>)\n })\n})\n",
+			"(*main.n)(0xADDR1)({\n Next: (*main.n)(0xADDR2)({\n" +
+				"  Next: (*main.n)(0xADDR1)(<already shown>)\n })\n})\n"},
+		{"<*>(0xc00001){a:1} 0xADDR7 x0xc00001",
+			"<*>(0xADDR1){a:1} 0xADDR7 x0xc00001"},
+	}
+
+	for i, test := range tests {
+		if got := spew.NormalizeAddresses(test.in); got != test.want {
+			t.Errorf("NormalizeAddresses #%d\n got: %q\nwant: %q", i, got,
+				test.want)
+		}
+	}
+
+	// Ensure the output of a real dump is the same for distinct allocations
+	// of the same structure.
+	type node struct{ Next *node }
+	a, b := &node{}, &node{}
+	a.Next, b.Next = a, b
+	if got, want := spew.NormalizeAddresses(spew.Sdump(a)),
+		spew.NormalizeAddresses(spew.Sdump(b)); got != want {
+		t.Errorf("NormalizeAddresses mismatch\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// TestDumpSize ensures DumpSize returns the exact number of bytes Dump would
+// output.
+func TestDumpSize(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+		Data []byte
+	}
+	n := &node{Name: "a", Data: []byte("some bytes to hexdump")}
+	n.Next = n
+
+	cs := &spew.ConfigState{Indent: "\t", MaxDepth: 2}
+	tests := []struct {
+		cs *spew.ConfigState
+		in []interface{}
+	}{
+		{cs, nil},
+		{cs, []interface{}{nil}},
+		{cs, []interface{}{5, "five"}},
+		{cs, []interface{}{n}},
+		{&spew.ConfigState{MaxOutputBytes: 10}, []interface{}{n}},
+	}
+	for i, test := range tests {
+		want := len(test.cs.Sdump(test.in...))
+		if got := test.cs.DumpSize(test.in...); got != want {
+			t.Errorf("DumpSize #%d: got %d, want %d", i, got, want)
+		}
+	}
+
+	if got, want := spew.DumpSize(n), len(spew.Sdump(n)); got != want {
+		t.Errorf("DumpSize: got %d, want %d", got, want)
+	}
+}
+
+// TestRegisterFormatter ensures registered formatters take precedence over
+// methods for both values and pointers, and that Clone and UnregisterFormatter
+// leave the original registrations intact.
+func TestRegisterFormatter(t *testing.T) {
+	type cents int64
+	type wrapper struct {
+		C  cents
+		S  stringer
+		PS *stringer
+	}
+	s := stringer("x")
+
+	cs := &spew.ConfigState{Indent: " ", DisablePointerAddresses: true}
+	cs.RegisterFormatter(reflect.TypeOf(cents(0)), func(w io.Writer, v reflect.Value, cfg *spew.ConfigState) {
+		fmt.Fprintf(w, "$%d.%02d", v.Int()/100, v.Int()%100)
+	})
+	cs.RegisterFormatter(reflect.TypeOf(&s), func(w io.Writer, v reflect.Value, cfg *spew.ConfigState) {
+		fmt.Fprintf(w, "registered %s", string(*v.Interface().(*stringer)))
+	})
+	clone := cs.Clone()
+	clone.UnregisterFormatter(reflect.TypeOf(cents(0)))
+
+	in := wrapper{C: 1234, S: "y", PS: &s}
+	tests := []struct {
+		cs   *spew.ConfigState
+		in   interface{}
+		want string
+	}{
+		{cs, cents(1234), "(spew_test.cents) $12.34\n"},
+		{cs, &s, "(*spew_test.stringer)((len=1) registered x)\n"},
+		{cs, s, "(spew_test.stringer) (len=1) stringer x\n"},
+		{cs, in, "(spew_test.wrapper) {\n C: (spew_test.cents) $12.34,\n" +
+			" S: (spew_test.stringer) (len=1) stringer y,\n" +
+			" PS: (*spew_test.stringer)((len=1) registered x)\n}\n"},
+		{clone, cents(1234), "(spew_test.cents) 1234\n"},
+		{clone, &s, "(*spew_test.stringer)((len=1) registered x)\n"},
+	}
+	for i, test := range tests {
+		if got := test.cs.Sdump(test.in); got != test.want {
+			t.Errorf("RegisterFormatter #%d\n got: %s want: %s", i, got,
+				test.want)
+		}
+	}
 }
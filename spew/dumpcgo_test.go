@@ -45,56 +45,51 @@ func addCgoDumpTests() {
 	addDumpTest(nv, "("+vt+")(<nil>)\n")
 
 	// C char array.
-	v2, v2l, v2c := testdata.GetCgoCharArray()
+	v2, v2l, _ := testdata.GetCgoCharArray()
 	v2Len := fmt.Sprintf("%d", v2l)
-	v2Cap := fmt.Sprintf("%d", v2c)
 	v2t := "[6]testdata._Ctype_char"
-	v2s := "(len=" + v2Len + " cap=" + v2Cap + ") " +
+	v2s := "(len=" + v2Len + ") " +
 		"{\n 00000000  74 65 73 74 32 00                               " +
 		"  |test2.|\n}"
 	addDumpTest(v2, "("+v2t+") "+v2s+"\n")
 
 	// C unsigned char array.
-	v3, v3l, v3c := testdata.GetCgoUnsignedCharArray()
+	v3, v3l, _ := testdata.GetCgoUnsignedCharArray()
 	v3Len := fmt.Sprintf("%d", v3l)
-	v3Cap := fmt.Sprintf("%d", v3c)
 	v3t := "[6]testdata._Ctype_unsignedchar"
 	v3t2 := "[6]testdata._Ctype_uchar"
-	v3s := "(len=" + v3Len + " cap=" + v3Cap + ") " +
+	v3s := "(len=" + v3Len + ") " +
 		"{\n 00000000  74 65 73 74 33 00                               " +
 		"  |test3.|\n}"
 	addDumpTest(v3, "("+v3t+") "+v3s+"\n", "("+v3t2+") "+v3s+"\n")
 
 	// C signed char array.
-	v4, v4l, v4c := testdata.GetCgoSignedCharArray()
+	v4, v4l, _ := testdata.GetCgoSignedCharArray()
 	v4Len := fmt.Sprintf("%d", v4l)
-	v4Cap := fmt.Sprintf("%d", v4c)
 	v4t := "[6]testdata._Ctype_schar"
 	v4t2 := "testdata._Ctype_schar"
-	v4s := "(len=" + v4Len + " cap=" + v4Cap + ") " +
+	v4s := "(len=" + v4Len + ") " +
 		"{\n (" + v4t2 + ") 116,\n (" + v4t2 + ") 101,\n (" + v4t2 +
 		") 115,\n (" + v4t2 + ") 116,\n (" + v4t2 + ") 52,\n (" + v4t2 +
 		") 0\n}"
 	addDumpTest(v4, "("+v4t+") "+v4s+"\n")
 
 	// C uint8_t array.
-	v5, v5l, v5c := testdata.GetCgoUint8tArray()
+	v5, v5l, _ := testdata.GetCgoUint8tArray()
 	v5Len := fmt.Sprintf("%d", v5l)
-	v5Cap := fmt.Sprintf("%d", v5c)
 	v5t := "[6]testdata._Ctype_uint8_t"
 	v5t2 := "[6]testdata._Ctype_uchar"
-	v5s := "(len=" + v5Len + " cap=" + v5Cap + ") " +
+	v5s := "(len=" + v5Len + ") " +
 		"{\n 00000000  74 65 73 74 35 00                               " +
 		"  |test5.|\n}"
 	addDumpTest(v5, "("+v5t+") "+v5s+"\n", "("+v5t2+") "+v5s+"\n")
 
 	// C typedefed unsigned char array.
-	v6, v6l, v6c := testdata.GetCgoTypdefedUnsignedCharArray()
+	v6, v6l, _ := testdata.GetCgoTypdefedUnsignedCharArray()
 	v6Len := fmt.Sprintf("%d", v6l)
-	v6Cap := fmt.Sprintf("%d", v6c)
 	v6t := "[6]testdata._Ctype_custom_uchar_t"
 	v6t2 := "[6]testdata._Ctype_uchar"
-	v6s := "(len=" + v6Len + " cap=" + v6Cap + ") " +
+	v6s := "(len=" + v6Len + ") " +
 		"{\n 00000000  74 65 73 74 36 00                               " +
 		"  |test6.|\n}"
 	addDumpTest(v6, "("+v6t+") "+v6s+"\n", "("+v6t2+") "+v6s+"\n")
@@ -224,3 +224,23 @@ func ExampleConfigState_Printf() {
 	// f: 1
 	// f: flagTwo
 }
+
+// This example demonstrates how to use NewConfig with options to create a
+// ConfigState and dump a variable with it.
+func ExampleNewConfig() {
+	// Create a ConfigState which sorts map keys and limits the depth.
+	scs := spew.NewConfig(spew.WithSortKeys(), spew.WithMaxDepth(1))
+
+	m := map[string][]int{"b": {2}, "a": {1}}
+	scs.Dump(m)
+
+	// Output:
+	// (map[string][]int) (len=2) {
+	//  (string) (len=1) "a": ([]int) (len=1 cap=1) {
+	//   <max depth reached>
+	//  },
+	//  (string) (len=1) "b": ([]int) (len=1 cap=1) {
+	//   <max depth reached>
+	//  }
+	// }
+}
@@ -36,6 +36,7 @@ type formatState struct {
 	fs             fmt.State
 	depth          int
 	pointers       map[uintptr]int
+	path           string
 	ignoreNextType bool
 	cs             *ConfigState
 }
@@ -101,12 +102,70 @@ func (f *formatState) unpackValue(v reflect.Value) reflect.Value {
 	return v
 }
 
+// formatPath formats the passed value which is located at the passed path
+// within the value being formatted.  When a Redact callback is configured, it
+// is invoked with the path and value, and the replacement it returns, if any,
+// is displayed in place of the value.
+func (f *formatState) formatPath(path string, v reflect.Value) {
+	if f.cs.Redact == nil {
+		f.format(v)
+		return
+	}
+
+	if replacement, ok := f.cs.Redact(path, v); ok {
+		if !f.ignoreNextType && f.fs.Flag('#') && v.IsValid() {
+			f.fs.Write(openParenBytes)
+			f.fs.Write([]byte(f.cs.typeName(v.Type())))
+			f.fs.Write(closeParenBytes)
+		}
+		f.ignoreNextType = false
+		f.fs.Write([]byte(replacement))
+		return
+	}
+
+	parentPath := f.path
+	f.path = path
+	f.format(v)
+	f.path = parentPath
+}
+
+// formatField formats the passed value of the named struct field.  The path to
+// the field is only built when it is needed for redaction.
+func (f *formatState) formatField(name string, v reflect.Value) {
+	if f.cs.Redact == nil {
+		f.format(v)
+		return
+	}
+	f.formatPath(fieldPath(f.path, name), v)
+}
+
+// formatIndex formats the passed value of the element at index i of an array
+// or slice.  The path to the element is only built when it is needed for
+// redaction.
+func (f *formatState) formatIndex(i int, v reflect.Value) {
+	if f.cs.Redact == nil {
+		f.format(v)
+		return
+	}
+	f.formatPath(indexPath(f.path, i), v)
+}
+
+// formatMapValue formats the passed value of the map entry for the passed key.
+// The path to the entry is only built when it is needed for redaction.
+func (f *formatState) formatMapValue(key, v reflect.Value) {
+	if f.cs.Redact == nil {
+		f.format(v)
+		return
+	}
+	f.formatPath(keyPath(f.cs, f.path, key), v)
+}
+
 // formatPtr handles formatting of pointers by indirecting them as necessary.
 func (f *formatState) formatPtr(v reflect.Value) {
 	// Display nil if top level pointer is nil.
 	showTypes := f.fs.Flag('#')
 	if v.IsNil() && (!showTypes || f.ignoreNextType) {
-		f.fs.Write(nilAngleBytes)
+		f.fs.Write(f.cs.nilMarker())
 		return
 	}
 
@@ -135,7 +194,9 @@ func (f *formatState) formatPtr(v reflect.Value) {
 		}
 		indirects++
 		addr := ve.Pointer()
-		pointerChain = append(pointerChain, addr)
+		if f.cs.MaxPointerChain <= 0 || len(pointerChain) <= f.cs.MaxPointerChain {
+			pointerChain = append(pointerChain, addr)
+		}
 		if pd, ok := f.pointers[addr]; ok && pd < f.depth {
 			cycleFound = true
 			indirects--
@@ -157,7 +218,7 @@ func (f *formatState) formatPtr(v reflect.Value) {
 	if showTypes && !f.ignoreNextType {
 		f.fs.Write(openParenBytes)
 		f.fs.Write(bytes.Repeat(asteriskBytes, indirects))
-		f.fs.Write([]byte(ve.Type().String()))
+		f.fs.Write([]byte(f.cs.typeName(ve.Type())))
 		f.fs.Write(closeParenBytes)
 	} else {
 		if nilFound || cycleFound {
@@ -175,7 +236,11 @@ func (f *formatState) formatPtr(v reflect.Value) {
 			if i > 0 {
 				f.fs.Write(pointerChainBytes)
 			}
-			printHexPtr(f.fs, addr)
+			if i > 0 && i == f.cs.MaxPointerChain {
+				f.fs.Write(ellipsisBytes)
+				break
+			}
+			printPtrOrNil(f.cs, f.fs, addr)
 		}
 		f.fs.Write(closeParenBytes)
 	}
@@ -183,10 +248,10 @@ func (f *formatState) formatPtr(v reflect.Value) {
 	// Display dereferenced value.
 	switch {
 	case nilFound:
-		f.fs.Write(nilAngleBytes)
+		f.fs.Write(f.cs.nilMarker())
 
 	case cycleFound:
-		f.fs.Write(circularShortBytes)
+		f.fs.Write(f.cs.circularMarker(circularShortBytes))
 
 	default:
 		f.ignoreNextType = true
@@ -215,7 +280,7 @@ func (f *formatState) format(v reflect.Value) {
 	// Print type information unless already handled elsewhere.
 	if !f.ignoreNextType && f.fs.Flag('#') {
 		f.fs.Write(openParenBytes)
-		f.fs.Write([]byte(v.Type().String()))
+		f.fs.Write([]byte(f.cs.typeName(v.Type())))
 		f.fs.Write(closeParenBytes)
 	}
 	f.ignoreNextType = false
@@ -230,6 +295,18 @@ func (f *formatState) format(v reflect.Value) {
 		}
 	}
 
+	// Detect slices and maps which contain themselves.  Unlike pointers, the
+	// data they reference is only tracked while it is being formatted since
+	// it is commonly shared, such as by subslices, without being circular.
+	if addr := refAddr(v); addr != 0 {
+		if pd, ok := f.pointers[addr]; ok && pd < f.depth {
+			f.fs.Write(f.cs.circularMarker(circularShortBytes))
+			return
+		}
+		f.pointers[addr] = f.depth
+		defer delete(f.pointers, addr)
+	}
+
 	switch kind {
 	case reflect.Invalid:
 		// Do nothing.  We should never get here since invalid has already
@@ -239,26 +316,30 @@ func (f *formatState) format(v reflect.Value) {
 		printBool(f.fs, v.Bool())
 
 	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
-		printInt(f.fs, v.Int(), 10)
+		if char, ok := runeChar(v.Int()); ok && f.cs.RuneAsChar && v.Type() == runeType {
+			f.fs.Write([]byte(char))
+			break
+		}
+		printInt(f.fs, v.Int(), f.cs.intBase())
 
 	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
-		printUint(f.fs, v.Uint(), 10)
+		printUint(f.fs, v.Uint(), f.cs.intBase())
 
 	case reflect.Float32:
-		printFloat(f.fs, v.Float(), 32)
+		printFloat(f.fs, v.Float(), 32, f.cs)
 
 	case reflect.Float64:
-		printFloat(f.fs, v.Float(), 64)
+		printFloat(f.fs, v.Float(), 64, f.cs)
 
 	case reflect.Complex64:
-		printComplex(f.fs, v.Complex(), 32)
+		printComplex(f.fs, v.Complex(), 32, f.cs)
 
 	case reflect.Complex128:
-		printComplex(f.fs, v.Complex(), 64)
+		printComplex(f.fs, v.Complex(), 64, f.cs)
 
 	case reflect.Slice:
 		if v.IsNil() {
-			f.fs.Write(nilAngleBytes)
+			f.fs.Write(f.cs.nilMarker())
 			break
 		}
 		fallthrough
@@ -266,29 +347,39 @@ func (f *formatState) format(v reflect.Value) {
 	case reflect.Array:
 		f.fs.Write(openBracketBytes)
 		f.depth++
-		if (f.cs.MaxDepth != 0) && (f.depth > f.cs.MaxDepth) {
+		if f.cs.depthExceeded(f.depth) {
 			f.fs.Write(maxShortBytes)
 		} else {
 			numEntries := v.Len()
-			for i := 0; i < numEntries; i++ {
+			numShown := limitEntries(numEntries, f.cs.MaxSliceElements)
+			for i := 0; i < numShown; i++ {
 				if i > 0 {
 					f.fs.Write(spaceBytes)
 				}
 				f.ignoreNextType = true
-				f.format(f.unpackValue(v.Index(i)))
+				f.formatIndex(i, f.unpackValue(v.Index(i)))
+			}
+			if numShown < numEntries {
+				f.fs.Write(spaceBytes)
+				printMore(f.fs, numEntries-numShown, moreElementsBytes)
 			}
 		}
 		f.depth--
 		f.fs.Write(closeBracketBytes)
 
 	case reflect.String:
-		f.fs.Write([]byte(v.String()))
+		str := v.String()
+		shown, truncated := truncateString(str, f.cs.MaxStringLength)
+		f.fs.Write([]byte(shown))
+		if truncated {
+			printTruncated(f.fs, len(str))
+		}
 
 	case reflect.Interface:
 		// The only time we should get here is for nil interfaces due to
 		// unpackValue calls.
 		if v.IsNil() {
-			f.fs.Write(nilAngleBytes)
+			f.fs.Write(f.cs.nilMarker())
 		}
 
 	case reflect.Ptr:
@@ -298,20 +389,24 @@ func (f *formatState) format(v reflect.Value) {
 	case reflect.Map:
 		// nil maps should be indicated as different than empty maps
 		if v.IsNil() {
-			f.fs.Write(nilAngleBytes)
+			f.fs.Write(f.cs.nilMarker())
 			break
 		}
 
 		f.fs.Write(openMapBytes)
 		f.depth++
-		if (f.cs.MaxDepth != 0) && (f.depth > f.cs.MaxDepth) {
+		if f.cs.depthExceeded(f.depth) {
 			f.fs.Write(maxShortBytes)
 		} else {
 			keys := v.MapKeys()
+			if f.cs.OmitZero {
+				keys = nonZeroKeys(v, keys)
+			}
 			if f.cs.SortKeys {
 				sortValues(keys, f.cs)
 			}
-			for i, key := range keys {
+			numShown := limitEntries(len(keys), f.cs.MaxMapEntries)
+			for i, key := range keys[:numShown] {
 				if i > 0 {
 					f.fs.Write(spaceBytes)
 				}
@@ -319,40 +414,56 @@ func (f *formatState) format(v reflect.Value) {
 				f.format(f.unpackValue(key))
 				f.fs.Write(colonBytes)
 				f.ignoreNextType = true
-				f.format(f.unpackValue(v.MapIndex(key)))
+				f.formatMapValue(key, f.unpackValue(v.MapIndex(key)))
+			}
+			if numShown < len(keys) {
+				f.fs.Write(spaceBytes)
+				printMore(f.fs, len(keys)-numShown, moreEntriesBytes)
 			}
 		}
 		f.depth--
 		f.fs.Write(closeMapBytes)
 
 	case reflect.Struct:
-		numFields := v.NumField()
+		if handleKnownTypes(f.cs, f.fs, v) {
+			break
+		}
+
 		f.fs.Write(openBraceBytes)
 		f.depth++
-		if (f.cs.MaxDepth != 0) && (f.depth > f.cs.MaxDepth) {
+		if f.cs.depthExceeded(f.depth) {
 			f.fs.Write(maxShortBytes)
 		} else {
 			vt := v.Type()
-			for i := 0; i < numFields; i++ {
+			fields := visibleFields(f.cs, vt)
+			if f.cs.OmitZero {
+				fields = nonZeroFields(v, fields)
+			}
+			numShown := limitEntries(len(fields), f.cs.MaxStructFields)
+			for i, fi := range fields[:numShown] {
 				if i > 0 {
 					f.fs.Write(spaceBytes)
 				}
-				vtf := vt.Field(i)
+				name := fieldName(vt.Field(fi))
 				if f.fs.Flag('+') || f.fs.Flag('#') {
-					f.fs.Write([]byte(vtf.Name))
+					f.fs.Write([]byte(name))
 					f.fs.Write(colonBytes)
 				}
-				f.format(f.unpackValue(v.Field(i)))
+				f.formatField(name, f.unpackValue(v.Field(fi)))
+			}
+			if numShown < len(fields) {
+				f.fs.Write(spaceBytes)
+				printMore(f.fs, len(fields)-numShown, moreFieldsBytes)
 			}
 		}
 		f.depth--
 		f.fs.Write(closeBraceBytes)
 
 	case reflect.Uintptr:
-		printHexPtr(f.fs, uintptr(v.Uint()))
+		printPtrOrNil(f.cs, f.fs, uintptr(v.Uint()))
 
 	case reflect.UnsafePointer, reflect.Chan, reflect.Func:
-		printHexPtr(f.fs, v.Pointer())
+		printPtrOrNil(f.cs, f.fs, v.Pointer())
 
 	// There were not any other types at the time this code was written, but
 	// fall back to letting the default fmt package handle it if any get added.
@@ -378,15 +489,26 @@ func (f *formatState) Format(fs fmt.State, verb rune) {
 		return
 	}
 
+	// Output a single line Go expression for %#v and %#+v when the GoSyntax
+	// option is set.
+	if fs.Flag('#') && f.cs.GoSyntax {
+		gcs := *f.cs
+		gcs.Compact = true
+		g := goSyntaxState{w: fs, cs: &gcs}
+		g.pointers = make(map[uintptr]bool)
+		g.dump(reflect.ValueOf(f.value), false)
+		return
+	}
+
 	if f.value == nil {
 		if fs.Flag('#') {
 			fs.Write(interfaceBytes)
 		}
-		fs.Write(nilAngleBytes)
+		fs.Write(f.cs.nilMarker())
 		return
 	}
 
-	f.format(reflect.ValueOf(f.value))
+	f.formatPath("", reflect.ValueOf(f.value))
 }
 
 // newFormatter is a helper function to consolidate the logic from the various
@@ -408,12 +530,13 @@ addresses), %#v (adds types), or %#+v (adds types and pointer addresses) verb
 combinations.  Any other verbs such as %x and %q will be sent to the the
 standard fmt package for formatting.  In addition, the custom formatter ignores
 the width and precision arguments (however they will still work on the format
-specifiers not handled by the custom formatter).
+specifiers not handled by the custom formatter).  When the GoSyntax option is
+set, %#v and %#+v instead output the value as a single line Go expression.
 
 Typically this function shouldn't be called directly.  It is much easier to make
 use of the custom formatter by calling one of the convenience functions such as
 Printf, Println, or Fprintf.
 */
 func NewFormatter(v interface{}) fmt.Formatter {
-	return newFormatter(&Config, v)
+	return newFormatter(Config.snapshot(), v)
 }
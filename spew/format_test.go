@@ -1365,6 +1365,22 @@ func addCircularFormatterTests() {
 	addFormatterTest("%#+v", v3, "("+v3t+")"+v3s7)
 	addFormatterTest("%#+v", pv3, "(*"+v3t+")("+v3Addr+")"+v3s8)
 	addFormatterTest("%#+v", &pv3, "(**"+v3t+")("+pv3Addr+"->"+v3Addr+")"+v3s8)
+
+	// Slice that contains itself.
+	v4 := make([]interface{}, 1)
+	v4[0] = v4
+	v4t := "[]interface {}"
+	addFormatterTest("%v", v4, "[<shown>]")
+	addFormatterTest("%#v", v4, "("+v4t+")[("+v4t+")<shown>]")
+
+	// Map that contains itself.
+	v5 := map[string]interface{}{}
+	v5["self"] = v5
+	addFormatterTest("%v", v5, "map[self:<shown>]")
+
+	// Slices that share data without being circular.
+	v6s := []int{1}
+	addFormatterTest("%v", []interface{}{v6s, [][]int{v6s}}, "[[1] [[1]]]")
 }
 
 func addPanicFormatterTests() {
@@ -0,0 +1,354 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// Some constants in the form of bytes used when producing Go syntax output.
+var (
+	goNilBytes           = []byte("nil")
+	ampersandBytes       = []byte("&")
+	sliceOpenBytes       = []byte("[]")
+	indexZeroBytes       = []byte("[0]")
+	circularCommentBytes = []byte("nil /* circular */")
+	maxDepthCommentBytes = []byte("/* max depth reached */")
+	openCommentBytes     = []byte(" /* ")
+	closeCommentBytes    = []byte(" */")
+)
+
+// goDefaultTypes maps kinds to the type untyped constants of that kind default
+// to.  Values of these types don't need a conversion to have the correct type
+// when their type isn't implied by the surrounding composite literal.
+var goDefaultTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:       reflect.TypeOf(false),
+	reflect.Int:        reflect.TypeOf(int(0)),
+	reflect.Float64:    reflect.TypeOf(float64(0)),
+	reflect.Complex128: reflect.TypeOf(complex128(0)),
+	reflect.String:     reflect.TypeOf(""),
+}
+
+// goSyntaxState contains information about the state of a Go syntax dump
+// operation.
+type goSyntaxState struct {
+	w        io.Writer
+	depth    int
+	pointers map[uintptr]bool
+	cs       *ConfigState
+}
+
+// indent performs indentation according to the passed level and cs.Indent
+// option.  No indentation is performed in compact mode.
+func (g *goSyntaxState) indent(level int) {
+	if g.cs.Compact {
+		return
+	}
+	g.w.Write(bytes.Repeat([]byte(g.cs.Indent), level))
+}
+
+// writeType outputs the passed type, wrapped in parentheses when it would be
+// ambiguous as the type of a conversion, such as for pointer types.
+func (g *goSyntaxState) writeType(t reflect.Type) {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Chan, reflect.Func:
+		if t.Name() == "" {
+			g.w.Write(openParenBytes)
+			g.w.Write([]byte(t.String()))
+			g.w.Write(closeParenBytes)
+			return
+		}
+	}
+	g.w.Write([]byte(t.String()))
+}
+
+// literal outputs the passed literal for a value of type t.  When the type
+// isn't implied by the context, the literal is converted to the type unless it
+// already has that type by default.
+func (g *goSyntaxState) literal(t reflect.Type, typed bool, lit []byte) {
+	convert := !typed && t != goDefaultTypes[t.Kind()]
+
+	// A float literal without a fraction or exponent would default to an int.
+	if !typed && t.Kind() == reflect.Float64 && !bytes.ContainsAny(lit, ".eE") {
+		convert = true
+	}
+	if !convert {
+		g.w.Write(lit)
+		return
+	}
+
+	g.writeType(t)
+	g.w.Write(openParenBytes)
+	g.w.Write(lit)
+	g.w.Write(closeParenBytes)
+}
+
+// floatSpecial outputs the passed Go expression for a NaN or infinite floating
+// point or complex value of type t.  The expression always has the default
+// type for its kind, so it is converted to any other type even when the type
+// is implied by the context.
+func (g *goSyntaxState) floatSpecial(t reflect.Type, expr []byte) {
+	g.literal(t, t == goDefaultTypes[t.Kind()], expr)
+}
+
+// composite outputs a composite literal of the passed type with n elements,
+// each of which is output by calling elem with its index.
+func (g *goSyntaxState) composite(t reflect.Type, n int, elem func(i int)) {
+	g.w.Write([]byte(t.String()))
+	if n == 0 {
+		g.w.Write(emptyBracesBytes)
+		return
+	}
+
+	g.depth++
+	switch {
+	case g.cs.depthExceeded(g.depth):
+		g.w.Write(openBraceBytes)
+		g.w.Write(maxDepthCommentBytes)
+		g.w.Write(closeBraceBytes)
+
+	case g.cs.Compact:
+		g.w.Write(openBraceBytes)
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				g.w.Write(commaSpaceBytes)
+			}
+			elem(i)
+		}
+		g.w.Write(closeBraceBytes)
+
+	default:
+		g.w.Write(openBraceNewlineBytes)
+		for i := 0; i < n; i++ {
+			g.indent(g.depth)
+			elem(i)
+			g.w.Write(commaNewlineBytes)
+		}
+		g.indent(g.depth - 1)
+		g.w.Write(closeBraceBytes)
+	}
+	g.depth--
+}
+
+// unexpressible outputs nil, converted to the value's type when the type isn't
+// implied by the context, for values such as channels and functions which
+// can't be expressed as a literal.  Non-nil values are followed by a comment
+// noting their type and address.
+func (g *goSyntaxState) unexpressible(v reflect.Value, typed bool) {
+	g.literal(v.Type(), typed, goNilBytes)
+	if v.IsNil() {
+		return
+	}
+	g.w.Write(openCommentBytes)
+	g.w.Write([]byte(v.Type().String()))
+	g.w.Write(spaceBytes)
+	printHexPtr(g.w, v.Pointer())
+	g.w.Write(closeCommentBytes)
+}
+
+// isComposite returns whether or not the passed value is output as a composite
+// literal, which can have its address taken directly.
+func isComposite(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Struct:
+		return true
+	case reflect.Slice, reflect.Map:
+		return !v.IsNil()
+	}
+	return false
+}
+
+// dump is the main workhorse for producing Go syntax output.  It uses the
+// passed reflect value to figure out what kind of object we are dealing with
+// and outputs the equivalent Go expression.  The typed flag indicates whether
+// or not the type of the value is implied by the surrounding composite literal
+// which allows untyped literals and nil to be used as is.  It is a recursive
+// function, however circular data structures are detected and output as nil
+// followed by a comment since they can't be expressed as a single expression.
+func (g *goSyntaxState) dump(v reflect.Value, typed bool) {
+	if !v.IsValid() {
+		g.w.Write(goNilBytes)
+		return
+	}
+
+	// Detect slices and maps which contain themselves in the same way as
+	// pointers.
+	if v.Kind() == reflect.Ptr && !v.IsNil() || refAddr(v) != 0 {
+		addr := v.Pointer()
+		if g.pointers[addr] {
+			g.w.Write(circularCommentBytes)
+			return
+		}
+		g.pointers[addr] = true
+		defer delete(g.pointers, addr)
+	}
+
+	t := v.Type()
+	switch kind := v.Kind(); kind {
+	case reflect.Bool:
+		lit := falseBytes
+		if v.Bool() {
+			lit = trueBytes
+		}
+		g.literal(t, typed, lit)
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		g.literal(t, typed, []byte(strconv.FormatInt(v.Int(), 10)))
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uint, reflect.Uintptr:
+		g.literal(t, typed, []byte(strconv.FormatUint(v.Uint(), 10)))
+
+	case reflect.Float32, reflect.Float64:
+		if expr, ok := floatSpecial(v.Float()); ok && g.cs.FloatSpecialAsString {
+			g.floatSpecial(t, expr)
+			break
+		}
+		precision := 64
+		if kind == reflect.Float32 {
+			precision = 32
+		}
+		g.literal(t, typed, []byte(strconv.FormatFloat(v.Float(), 'g', -1, precision)))
+
+	case reflect.Complex64, reflect.Complex128:
+		precision := 64
+		if kind == reflect.Complex64 {
+			precision = 32
+		}
+		c := v.Complex()
+		_, realSpecial := floatSpecial(real(c))
+		_, imagSpecial := floatSpecial(imag(c))
+		if (realSpecial || imagSpecial) && g.cs.FloatSpecialAsString {
+			var buf bytes.Buffer
+			printComplex(&buf, c, precision, &ConfigState{FloatSpecialAsString: true})
+			g.floatSpecial(t, buf.Bytes())
+			break
+		}
+		var buf bytes.Buffer
+		printComplex(&buf, c, precision, nil)
+		g.literal(t, typed, buf.Bytes())
+
+	case reflect.String:
+		g.literal(t, typed, []byte(strconv.Quote(v.String())))
+
+	case reflect.Interface:
+		if v.IsNil() {
+			g.literal(t, typed, goNilBytes)
+			break
+		}
+		g.dump(v.Elem(), false)
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			g.literal(t, typed, goNilBytes)
+			break
+		}
+
+		// Composite literals can have their address taken directly while
+		// other values are wrapped in a single element slice literal whose
+		// element has its address taken instead.
+		g.w.Write(ampersandBytes)
+		elem := v.Elem()
+		if isComposite(elem) {
+			g.dump(elem, true)
+			break
+		}
+		g.w.Write(sliceOpenBytes)
+		g.w.Write([]byte(elem.Type().String()))
+		g.w.Write(openBraceBytes)
+		g.dump(elem, true)
+		g.w.Write(closeBraceBytes)
+		g.w.Write(indexZeroBytes)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			g.literal(t, typed, goNilBytes)
+			break
+		}
+		fallthrough
+
+	case reflect.Array:
+		g.composite(t, v.Len(), func(i int) {
+			g.dump(v.Index(i), true)
+		})
+
+	case reflect.Map:
+		if v.IsNil() {
+			g.literal(t, typed, goNilBytes)
+			break
+		}
+		keys := v.MapKeys()
+		if g.cs.OmitZero {
+			keys = nonZeroKeys(v, keys)
+		}
+		if g.cs.SortKeys {
+			sortValues(keys, g.cs)
+		}
+		g.composite(t, len(keys), func(i int) {
+			g.dump(keys[i], true)
+			g.w.Write(colonSpaceBytes)
+			g.dump(v.MapIndex(keys[i]), true)
+		})
+
+	case reflect.Struct:
+		fields := visibleFields(g.cs, t)
+		if g.cs.OmitZero {
+			fields = nonZeroFields(v, fields)
+		}
+		g.composite(t, len(fields), func(i int) {
+			g.w.Write([]byte(t.Field(fields[i]).Name))
+			g.w.Write(colonSpaceBytes)
+			g.dump(v.Field(fields[i]), true)
+		})
+
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		g.unexpressible(v, typed)
+
+	// There were not any other types at the time this code was written, but
+	// fall back to nil followed by a comment with the type in case any new
+	// types are added.
+	default:
+		g.w.Write(goNilBytes)
+		g.w.Write(openCommentBytes)
+		g.w.Write([]byte(t.String()))
+		g.w.Write(closeCommentBytes)
+	}
+}
+
+// dumpArg outputs the passed top-level argument as a Go expression.  Like the
+// dumpArg method of dumpState, any panic which occurs while traversing it is
+// recovered and displayed in place of the remainder of the argument.
+func (g *goSyntaxState) dumpArg(arg interface{}) {
+	v := reflect.ValueOf(arg)
+	defer catchPanic(g.w, v)
+	g.dump(v, false)
+}
+
+// fgodump is a helper function to consolidate the logic for producing Go
+// syntax output from the various public dump methods when the GoSyntax option
+// is set.  Each argument is output as a Go expression followed by a newline.
+func fgodump(cs *ConfigState, w io.Writer, a ...interface{}) {
+	for _, arg := range a {
+		g := goSyntaxState{w: w, cs: cs}
+		g.pointers = make(map[uintptr]bool)
+		g.dumpArg(arg)
+		g.w.Write(newlineBytes)
+	}
+}
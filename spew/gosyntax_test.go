@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"fmt"
+	"go/parser"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// goSyntaxTest is used to describe a test to be performed against the Go
+// syntax output mode of the Dump methods.
+type goSyntaxTest struct {
+	cs   *spew.ConfigState
+	in   interface{}
+	want string
+}
+
+// TestGoSyntax executes all of the tests described by goSyntaxTests.
+func TestGoSyntax(t *testing.T) {
+	scsGo := &spew.ConfigState{Indent: "\t", GoSyntax: true, SortKeys: true}
+	scsGoCompact := &spew.ConfigState{GoSyntax: true, Compact: true,
+		SortKeys: true}
+	scsGoMaxDepth := &spew.ConfigState{GoSyntax: true, Compact: true,
+		MaxDepth: 1}
+	scsGoOmitZero := &spew.ConfigState{GoSyntax: true, Compact: true,
+		OmitZero: true}
+	scsGoFloatSpecial := &spew.ConfigState{GoSyntax: true, Compact: true,
+		FloatSpecialAsString: true}
+
+	var nilPtr *int
+	var nilSlice []int
+	var nilMap map[string]int
+	i := 5
+	s1 := struct {
+		a int
+		B string
+	}{1, "two"}
+	m := map[string]int{"one": 1, "two": 2}
+	ch := make(chan int)
+
+	tests := []goSyntaxTest{
+		{scsGo, nil, "nil"},
+		{scsGo, true, "true"},
+		{scsGo, 5, "5"},
+		{scsGo, int8(-5), "int8(-5)"},
+		{scsGo, uint64(18446744073709551615), "uint64(18446744073709551615)"},
+		{scsGo, float32(1.5), "float32(1.5)"},
+		{scsGo, 2.5, "2.5"},
+		{scsGo, 2.0, "float64(2)"},
+		{scsGo, complex(1, 2), "(1+2i)"},
+		{scsGo, complex64(complex(1, 2)), "complex64((1+2i))"},
+		{scsGo, "a \"quoted\"\n", `"a \"quoted\"\n"`},
+		{scsGo, stringer("test"), `spew_test.stringer("test")`},
+		{scsGo, nilPtr, "(*int)(nil)"},
+		{scsGo, nilSlice, "[]int(nil)"},
+		{scsGo, nilMap, "map[string]int(nil)"},
+		{scsGo, &i, "&[]int{5}[0]"},
+		{scsGo, []int{}, "[]int{}"},
+		{scsGo, []int{1, 2}, "[]int{\n\t1,\n\t2,\n}"},
+		{scsGo, [2]string{"a", "b"}, "[2]string{\n\t\"a\",\n\t\"b\",\n}"},
+		{scsGo, m, "map[string]int{\n\t\"one\": 1,\n\t\"two\": 2,\n}"},
+		{scsGo, &s1, "&struct { a int; B string }{\n\ta: 1,\n\tB: \"two\",\n}"},
+		{scsGo, []interface{}{1, uint(2), nil}, "[]interface {}{\n\t1,\n\tuint(2),\n\tnil,\n}"},
+		{scsGo, (func())(nil), "(func())(nil)"},
+		{scsGo, ch, fmt.Sprintf("(chan int)(nil) /* chan int %p */", ch)},
+		{scsGoCompact, [][]int{{1}, nil}, "[][]int{[]int{1}, nil}"},
+		{scsGoCompact, tagSkip{1, "pw", 2}, "spew_test.tagSkip{A: 1, B: 2}"},
+		{scsGoCompact, tagRename{UserID: 1}, "spew_test.tagRename{UserID: 1, name: \"\", Dash: 0, Plain: 0}"},
+		{scsGoMaxDepth, [][]int{{1}}, "[][]int{[]int{/* max depth reached */}}"},
+		{scsGoOmitZero, tagSkip{0, "pw", 2}, "spew_test.tagSkip{B: 2}"},
+		{scsGoFloatSpecial, math.NaN(), "math.NaN()"},
+		{scsGoFloatSpecial, float32(math.Inf(-1)), "float32(math.Inf(-1))"},
+		{scsGoFloatSpecial, []float32{1, float32(math.Inf(1))}, "[]float32{1, float32(math.Inf(1))}"},
+		{scsGoFloatSpecial, complex(math.NaN(), 1), "complex(math.NaN(), 1)"},
+		{scsGoFloatSpecial, complex64(complex(1, math.Inf(1))), "complex64(complex(1, math.Inf(1)))"},
+	}
+
+	for i, test := range tests {
+		got := strings.TrimSuffix(test.cs.Sdump(test.in), "\n")
+		if got != test.want {
+			t.Errorf("GoSyntax #%d\n got: %s want: %s", i, got, test.want)
+			continue
+		}
+		if _, err := parser.ParseExpr(got); err != nil {
+			t.Errorf("GoSyntax #%d produced invalid Go: %s (%v)", i, got, err)
+		}
+	}
+}
+
+// TestGoSyntaxFormatter ensures the Formatter outputs a single line Go
+// expression for %#v only when the GoSyntax option is set.
+func TestGoSyntaxFormatter(t *testing.T) {
+	cs := &spew.ConfigState{GoSyntax: true, SortKeys: true}
+	v := map[string][]int{"b": {2}, "a": nil}
+
+	tests := []struct {
+		cs     *spew.ConfigState
+		format string
+		in     interface{}
+		want   string
+	}{
+		{cs, "%#v", v, `map[string][]int{"a": nil, "b": []int{2}}`},
+		{cs, "%#+v", uint8(3), "uint8(3)"},
+		{cs, "%#v", nil, "nil"},
+		{cs, "%v", v, "map[a:<nil> b:[2]]"},
+		{cs, "%x", 255, "ff"},
+		{&spew.ConfigState{SortKeys: true}, "%#v", v,
+			"(map[string][]int)map[a:<nil> b:[2]]"},
+	}
+
+	for i, test := range tests {
+		got := test.cs.Sprintf(test.format, test.in)
+		if got != test.want {
+			t.Errorf("GoSyntax Formatter #%d\n got: %s want: %s", i, got,
+				test.want)
+		}
+	}
+}
+
+// TestGoSyntaxCircular ensures circular references are detected and output as
+// nil followed by a comment.
+func TestGoSyntaxCircular(t *testing.T) {
+	cs := &spew.ConfigState{GoSyntax: true, Compact: true}
+	c := &jsonCircular{Name: "loop"}
+	c.Next = c
+
+	got := strings.TrimSuffix(cs.Sdump(c), "\n")
+	want := `&spew_test.jsonCircular{Name: "loop", Next: nil /* circular */}`
+	if got != want {
+		t.Errorf("GoSyntax circular\n got: %s want: %s", got, want)
+	}
+
+	// Ensure each argument is output on its own line.
+	got = cs.Sdump(1, "two")
+	if got != "1\n\"two\"\n" {
+		t.Errorf("GoSyntax multiple arguments\n got: %q want: %q", got,
+			"1\n\"two\"\n")
+	}
+}
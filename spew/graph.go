@@ -0,0 +1,295 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// dotEscaper escapes the text of labels in GraphViz DOT output.  Newlines end
+// left-justified lines.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\l`)
+
+// graphEdge is an edge of the graph produced by Gdump which leads to the node
+// with the id to and is labeled with the name of the field, element, or map
+// entry it represents.
+type graphEdge struct {
+	name string
+	to   int
+}
+
+// graphNode is a node of the graph produced by Gdump.  Its label consists of
+// its type followed by a line for each of its scalar fields, elements, or map
+// entries, while the others are represented by edges to their own nodes.
+type graphNode struct {
+	label []string
+	edges []graphEdge
+}
+
+// graphKey identifies the value a node represents by its address, type, and
+// length so values which are referenced more than once share a single node.
+// The type and length are needed since a struct and its first field, as well
+// as slices of different lengths, can share an address.
+type graphKey struct {
+	addr uintptr
+	t    reflect.Type
+	len  int
+}
+
+// graphState contains information about the state of a graph operation.
+type graphState struct {
+	cs    *ConfigState
+	nodes []*graphNode
+	ids   map[graphKey]int
+	depth int
+}
+
+// add adds a node with the passed first label line to the graph and returns
+// its id along with the node.
+func (g *graphState) add(label string) (int, *graphNode) {
+	n := &graphNode{label: []string{label}}
+	g.nodes = append(g.nodes, n)
+	return len(g.nodes) - 1, n
+}
+
+// more adds a line to the label of node n which notes that the passed number
+// of entries of the type described by what weren't added due to a limit.
+func (g *graphState) more(n *graphNode, count int, what []byte) {
+	var buf bytes.Buffer
+	printMore(&buf, count, what)
+	n.label = append(n.label, buf.String())
+}
+
+// methodText returns the text produced by the error or Stringer interface of
+// the passed value, when methods are enabled and it implements one of them.
+func (g *graphState) methodText(v reflect.Value) (string, bool) {
+	if g.cs.DisableMethods || !v.IsValid() || v.Kind() == reflect.Interface {
+		return "", false
+	}
+	var buf bytes.Buffer
+	if !handleMethods(g.cs, &buf, v) {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// scalar returns the text a value which doesn't have a node of its own is
+// displayed as in the label of the node which contains it.
+func (g *graphState) scalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return string(g.cs.nilMarker())
+	case reflect.String:
+		return strconv.Quote(v.String())
+	}
+	return formatKey(g.cs, v)
+}
+
+// header returns the first line of the label of the node for the passed
+// value, which is its type along with the length of slices and maps.
+func (g *graphState) header(v reflect.Value) string {
+	header := g.cs.typeName(v.Type())
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		header += " (len=" + strconv.Itoa(v.Len()) + ")"
+	}
+	return header
+}
+
+// member adds the passed value, which is the field, element, or map entry of
+// node n with the passed name, to the node.  Values which have nodes of their
+// own are added as edges to them and the others as lines of the label.
+func (g *graphState) member(n *graphNode, name string, v reflect.Value) {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	text, ok := g.methodText(v)
+	if !ok {
+		if to, ok := g.target(v); ok {
+			n.edges = append(n.edges, graphEdge{name: name, to: to})
+			return
+		}
+		text = g.scalar(v)
+	}
+	if name != "" {
+		text = name + ": " + text
+	}
+	n.label = append(n.label, text)
+}
+
+// target returns the id of the node which represents the passed value in the
+// graph, adding it and the nodes reachable from it the first time it is
+// encountered.  Non-nil pointers are represented by the node of the value they
+// point to, while structs, arrays, and non-empty slices and maps have nodes of
+// their own.  Nodes of values which are referenced by address are shared, so
+// circular references become edges back to existing nodes.  The boolean return
+// is false for other values, which are displayed as text instead.
+func (g *graphState) target(v reflect.Value) (int, bool) {
+	var key graphKey
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return 0, false
+		}
+		key = graphKey{addr: v.Pointer(), t: v.Type()}
+		v = v.Elem()
+
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() || v.Len() == 0 {
+			return 0, false
+		}
+		key = graphKey{addr: v.Pointer(), t: v.Type(), len: v.Len()}
+
+	case reflect.Struct, reflect.Array:
+
+	default:
+		return 0, false
+	}
+
+	if key.addr != 0 {
+		if id, ok := g.ids[key]; ok {
+			return id, true
+		}
+	}
+	id, n := g.add(g.header(v))
+	if key.addr != 0 {
+		g.ids[key] = id
+	}
+
+	// The members of nodes nested beyond the maximum depth aren't added.
+	g.depth++
+	defer func() { g.depth-- }()
+	if g.cs.depthExceeded(g.depth) {
+		n.label = append(n.label, string(maxDepthBytes))
+		return id, true
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		numEntries := v.Len()
+		numShown := limitEntries(numEntries, g.cs.MaxSliceElements)
+		for i := 0; i < numShown; i++ {
+			g.member(n, "["+strconv.Itoa(i)+"]", v.Index(i))
+		}
+		if numShown < numEntries {
+			g.more(n, numEntries-numShown, moreElementsBytes)
+		}
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		if g.cs.OmitZero {
+			keys = nonZeroKeys(v, keys)
+		}
+		if g.cs.SortKeys {
+			sortValues(keys, g.cs)
+		}
+		numShown := limitEntries(len(keys), g.cs.MaxMapEntries)
+		for _, key := range keys[:numShown] {
+			g.member(n, formatKey(g.cs, key), v.MapIndex(key))
+		}
+		if numShown < len(keys) {
+			g.more(n, len(keys)-numShown, moreEntriesBytes)
+		}
+
+	case reflect.Struct:
+		vt := v.Type()
+		fields := visibleFields(g.cs, vt)
+		if g.cs.OmitZero {
+			fields = nonZeroFields(v, fields)
+		}
+		numShown := limitEntries(len(fields), g.cs.MaxStructFields)
+		for _, i := range fields[:numShown] {
+			g.member(n, fieldName(vt.Field(i)), v.Field(i))
+		}
+		if numShown < len(fields) {
+			g.more(n, len(fields)-numShown, moreFieldsBytes)
+		}
+
+	// The value pointed to is displayed in the label of its node when it
+	// doesn't have any members.
+	default:
+		g.member(n, "", v)
+	}
+	return id, true
+}
+
+// write outputs the graph in the GraphViz DOT language to Writer w.
+func (g *graphState) write(w io.Writer) {
+	io.WriteString(w, "digraph spew {\n\tnode [shape=box];\n")
+	for id, n := range g.nodes {
+		label := strings.Join(n.label, "\n") + "\n"
+		fmt.Fprintf(w, "\tn%d [label=\"%s\"];\n", id, dotEscaper.Replace(label))
+		for _, e := range n.edges {
+			if e.name == "" {
+				fmt.Fprintf(w, "\tn%d -> n%d;\n", id, e.to)
+				continue
+			}
+			fmt.Fprintf(w, "\tn%d -> n%d [label=\"%s\"];\n", id, e.to,
+				dotEscaper.Replace(e.name))
+		}
+	}
+	io.WriteString(w, "}\n")
+}
+
+// fgdump is a helper function to consolidate the logic from the various public
+// graph methods which take varying config states.
+func fgdump(cs *ConfigState, w io.Writer, v interface{}) (n int, err error) {
+	cs = cs.snapshot()
+	g := graphState{cs: cs, ids: make(map[graphKey]int)}
+
+	// The passed value is displayed in the label of a node of its own when it
+	// isn't otherwise represented by one.
+	rv := reflect.ValueOf(v)
+	text, ok := g.methodText(rv)
+	switch {
+	case !rv.IsValid():
+		g.add(g.scalar(rv))
+
+	case ok:
+		_, node := g.add(cs.typeName(rv.Type()))
+		node.label = append(node.label, text)
+
+	default:
+		if _, ok := g.target(rv); !ok {
+			_, node := g.add(cs.typeName(rv.Type()))
+			node.label = append(node.label, g.scalar(rv))
+		}
+	}
+
+	ew := &errWriter{w: w}
+	g.write(ew)
+	return ew.n, ew.err
+}
+
+// Gdump outputs the graph of the passed value to io.Writer w in the GraphViz
+// DOT language, which can be rendered with the dot command, for visualizing
+// complex data structures with shared and circular references.  Each distinct
+// pointed to value, struct, array, slice, and map is a node labeled with its
+// type and scalar members, while the members which reference other nodes are
+// edges labeled with their field name, index, or map key.  Values which are
+// referenced more than once appear once with an edge from each reference, and
+// circular references are edges back to nodes that were already output.  It
+// returns the number of bytes written and the first write error encountered,
+// after which nothing more is written.
+func Gdump(w io.Writer, v interface{}) (n int, err error) {
+	return fgdump(&Config, w, v)
+}
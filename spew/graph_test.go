@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// graphNode is used to test graphing shared and circular structures.
+type graphNode struct {
+	Name  string
+	Next  *graphNode
+	Tags  []string
+	Peers map[string]*graphNode
+}
+
+// TestGdump ensures Gdump outputs the expected GraphViz DOT graph.
+func TestGdump(t *testing.T) {
+	cs := &spew.ConfigState{SortKeys: true}
+	a := &graphNode{Name: "a"}
+	b := &graphNode{Name: `"b"`, Next: a, Tags: []string{"x"}}
+	a.Next = b
+	a.Peers = map[string]*graphNode{"self": a, "b": b}
+	n := 5
+
+	header := "digraph spew {\n\tnode [shape=box];\n"
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{nil, header + "\tn0 [label=\"<nil>\\l\"];\n}\n"},
+		{5, header + "\tn0 [label=\"int\\l5\\l\"];\n}\n"},
+		{&n, header + "\tn0 [label=\"int\\l5\\l\"];\n}\n"},
+		{a, header +
+			"\tn0 [label=\"spew_test.graphNode\\lName: \\\"a\\\"\\lTags: <nil>\\l\"];\n" +
+			"\tn0 -> n1 [label=\"Next\"];\n" +
+			"\tn0 -> n3 [label=\"Peers\"];\n" +
+			"\tn1 [label=\"spew_test.graphNode\\lName: \\\"\\\\\\\"b\\\\\\\"\\\"\\lPeers: <nil>\\l\"];\n" +
+			"\tn1 -> n0 [label=\"Next\"];\n" +
+			"\tn1 -> n2 [label=\"Tags\"];\n" +
+			"\tn2 [label=\"[]string (len=1)\\l[0]: \\\"x\\\"\\l\"];\n" +
+			"\tn3 [label=\"map[string]*spew_test.graphNode (len=2)\\l\"];\n" +
+			"\tn3 -> n1 [label=\"b\"];\n" +
+			"\tn3 -> n0 [label=\"self\"];\n}\n"},
+		{[]interface{}{1, nil, errors.New("oops")}, header +
+			"\tn0 [label=\"[]interface {} (len=3)\\l[0]: 1\\l[1]: <nil>\\l" +
+			"[2]: oops\\l\"];\n}\n"},
+		{struct{ A [1]int }{}, header +
+			"\tn0 [label=\"struct { A [1]int }\\l\"];\n" +
+			"\tn0 -> n1 [label=\"A\"];\n" +
+			"\tn1 [label=\"[1]int\\l[0]: 0\\l\"];\n}\n"},
+	}
+
+	for i, test := range tests {
+		var buf bytes.Buffer
+		n, err := cs.Gdump(&buf, test.in)
+		if err != nil || n != buf.Len() {
+			t.Errorf("Gdump #%d: got %d bytes (err %v), want %d", i, n, err,
+				buf.Len())
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("Gdump #%d\n got: %s\nwant: %s", i, got, test.want)
+		}
+	}
+
+	// Ensure the global config is used by the top-level function.
+	var buf bytes.Buffer
+	spew.Gdump(&buf, 5)
+	if want := header + "\tn0 [label=\"int\\l5\\l\"];\n}\n"; buf.String() != want {
+		t.Errorf("Gdump\n got: %s\nwant: %s", buf.String(), want)
+	}
+}
+
+// TestGdumpLimits ensures Gdump honors the depth, collection limit and zero
+// value options.
+func TestGdumpLimits(t *testing.T) {
+	type pair struct {
+		A, B int
+		C    []int
+	}
+	header := "digraph spew {\n\tnode [shape=box];\n"
+	tests := []struct {
+		cs   *spew.ConfigState
+		in   interface{}
+		want string
+	}{
+		{&spew.ConfigState{MaxDepth: 1}, pair{C: []int{1}}, header +
+			"\tn0 [label=\"spew_test.pair\\lA: 0\\lB: 0\\l\"];\n" +
+			"\tn0 -> n1 [label=\"C\"];\n" +
+			"\tn1 [label=\"[]int (len=1)\\l<max depth reached>\\l\"];\n}\n"},
+		{&spew.ConfigState{MaxSliceElements: 2}, []int{1, 2, 3, 4}, header +
+			"\tn0 [label=\"[]int (len=4)\\l[0]: 1\\l[1]: 2\\l" +
+			"... (2 more elements)\\l\"];\n}\n"},
+		{&spew.ConfigState{MaxMapEntries: 1, SortKeys: true},
+			map[string]int{"a": 1, "b": 2}, header +
+				"\tn0 [label=\"map[string]int (len=2)\\la: 1\\l" +
+				"... (1 more entries)\\l\"];\n}\n"},
+		{&spew.ConfigState{MaxStructFields: 1}, pair{A: 1}, header +
+			"\tn0 [label=\"spew_test.pair\\lA: 1\\l... (2 more fields)\\l\"];\n}\n"},
+		{&spew.ConfigState{OmitZero: true}, pair{B: 2}, header +
+			"\tn0 [label=\"spew_test.pair\\lB: 2\\l\"];\n}\n"},
+		{&spew.ConfigState{OmitZero: true, SortKeys: true},
+			map[string]int{"a": 0, "b": 2}, header +
+				"\tn0 [label=\"map[string]int (len=2)\\lb: 2\\l\"];\n}\n"},
+	}
+
+	for i, test := range tests {
+		var buf bytes.Buffer
+		test.cs.Gdump(&buf, test.in)
+		if got := buf.String(); got != test.want {
+			t.Errorf("Gdump #%d\n got: %s\nwant: %s", i, got, test.want)
+		}
+	}
+}
@@ -77,8 +77,35 @@ func TestInvalidReflectValue(t *testing.T) {
 	}
 }
 
-// SortValues makes the internal sortValues function available to the test
-// package.
-func SortValues(values []reflect.Value, cs *ConfigState) {
-	sortValues(values, cs)
+// TestShortTypeName ensures the directories of package paths are removed from
+// type names while the rest of the name is unchanged.
+func TestShortTypeName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"int", "int"},
+		{"pkg.Thing", "pkg.Thing"},
+		{"github.com/org/proj/internal/pkg.Thing", "pkg.Thing"},
+		{"*[]map[string]github.com/org/proj/pkg.Thing", "*[]map[string]pkg.Thing"},
+		{"pkg.Pair[github.com/a/b.K,gopkg.in/c-d.v2/e.V]", "pkg.Pair[b.K,e.V]"},
+		{"func(example.com/x.In) example.com/y.Out", "func(x.In) y.Out"},
+	}
+
+	for i, test := range tests {
+		if got := shortTypeName(test.in); got != test.want {
+			t.Errorf("shortTypeName #%d\n got: %s want: %s", i, got, test.want)
+		}
+	}
+
+	// Ensure the option is honored by Dump and the Formatter.
+	cs := ConfigState{ShortTypeNames: true}
+	type thing struct{}
+	v := []thing{}
+	if got := cs.Sdump(v); got != "([]spew.thing) {}\n" {
+		t.Errorf("ShortTypeNames Dump got: %q", got)
+	}
+	if got := cs.Sprintf("%#v", v); got != "([]spew.thing)[]" {
+		t.Errorf("ShortTypeNames Formatter got: %q", got)
+	}
 }
@@ -0,0 +1,390 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Some constants in the form of bytes used when producing JSON output.
+var (
+	nullBytes         = []byte("null")
+	commaBytes        = []byte(",")
+	refOpenBytes      = []byte(`{"$ref":"`)
+	refCloseBytes     = []byte(`"}`)
+	maxDepthJSONBytes = []byte(`"<max depth reached>"`)
+)
+
+// jsonState contains information about the state of a JSON dump operation.
+type jsonState struct {
+	w        io.Writer
+	depth    int
+	pointers map[uintptr]bool
+	path     string
+	cs       *ConfigState
+}
+
+// writeJSONString outputs the passed string as a quoted JSON string to Writer
+// w.  Invalid UTF-8 sequences are replaced with the Unicode replacement
+// character so the result is always valid JSON.
+func writeJSONString(w io.Writer, s string) {
+	buf := make([]byte, 0, len(s)+2)
+	buf = append(buf, '"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			buf = append(buf, '\\', byte(r))
+		case r == '\n':
+			buf = append(buf, '\\', 'n')
+		case r == '\r':
+			buf = append(buf, '\\', 'r')
+		case r == '\t':
+			buf = append(buf, '\\', 't')
+		case r < 0x20:
+			buf = append(buf, '\\', 'u', '0', '0', hexDigits[r>>4],
+				hexDigits[r&0xf])
+		default:
+			buf = append(buf, string(r)...)
+		}
+	}
+	buf = append(buf, '"')
+	w.Write(buf)
+}
+
+// writeFloat outputs a floating point value as a JSON number to Writer w.
+// NaN and infinities can't be represented as JSON numbers, so they are output
+// as strings instead.
+func (j *jsonState) writeFloat(val float64, precision int) {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		writeJSONString(j.w, strconv.FormatFloat(val, 'g', -1, precision))
+		return
+	}
+	printFloat(j.w, val, precision, nil)
+}
+
+// writeAsString outputs whatever fn writes as a JSON string to Writer w.  It
+// is used for values which have no natural JSON representation.
+func (j *jsonState) writeAsString(fn func(w io.Writer)) {
+	var buf bytes.Buffer
+	fn(&buf)
+	writeJSONString(j.w, buf.String())
+}
+
+// mapKey returns the string to use as the JSON object key for the passed map
+// key.  String keys are used as is while other keys are formatted the same as
+// the Formatter would display them.
+func (j *jsonState) mapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	return formatKey(j.cs, key)
+}
+
+// tracksPaths returns whether or not the paths to values need to be tracked,
+// which is only the case when they are passed to a Redact or OmitFunc
+// callback.
+func (j *jsonState) tracksPaths() bool {
+	return j.cs.Redact != nil || j.cs.OmitFunc != nil
+}
+
+// dumpPath outputs the passed value which is located at the passed path within
+// the value being dumped.  When a Redact callback is configured, it is invoked
+// with the path and value, and the replacement it returns, if any, is output as
+// a string in place of the value.
+func (j *jsonState) dumpPath(path string, v reflect.Value) {
+	if !j.tracksPaths() {
+		j.dump(v)
+		return
+	}
+
+	if j.cs.Redact != nil {
+		if replacement, ok := j.cs.Redact(path, v); ok {
+			writeJSONString(j.w, replacement)
+			return
+		}
+	}
+
+	parentPath := j.path
+	j.path = path
+	j.dump(v)
+	j.path = parentPath
+}
+
+// dump is the main workhorse for producing JSON output.  It uses the passed
+// reflect value to figure out what kind of object we are dealing with and
+// outputs the equivalent JSON.  It is a recursive function, however circular
+// data structures are detected and output as a reference to the address which
+// was already being dumped.
+func (j *jsonState) dump(v reflect.Value) {
+	kind := v.Kind()
+	switch kind {
+	case reflect.Invalid:
+		j.w.Write(nullBytes)
+		return
+
+	case reflect.Interface:
+		if v.IsNil() {
+			j.w.Write(nullBytes)
+			return
+		}
+		j.dump(v.Elem())
+		return
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			j.w.Write(nullBytes)
+			return
+		}
+		addr := v.Pointer()
+		if j.pointers[addr] {
+			j.w.Write(refOpenBytes)
+			printHexPtr(j.w, addr)
+			j.w.Write(refCloseBytes)
+			return
+		}
+		j.pointers[addr] = true
+		j.dump(v.Elem())
+		delete(j.pointers, addr)
+		return
+	}
+
+	// Let registered formatters, types which implement the Dumper interface,
+	// and Stringer/error interfaces output values the same as the usual format
+	// unless doing so is disabled.  The result is output as a string.
+	var buf bytes.Buffer
+	handled := handleFormatter(j.cs, &buf, v)
+	if !handled && !j.cs.DisableDumperInterface {
+		handled = handleDumper(j.cs, &buf, v)
+	}
+	if !handled && !j.cs.DisableMethods {
+		handled = handleMethods(j.cs, &buf, v)
+	}
+	if handled {
+		writeJSONString(j.w, buf.String())
+		return
+	}
+
+	// Detect slices and maps which contain themselves in the same way as
+	// pointers.
+	if addr := refAddr(v); addr != 0 {
+		if j.pointers[addr] {
+			j.w.Write(refOpenBytes)
+			printHexPtr(j.w, addr)
+			j.w.Write(refCloseBytes)
+			return
+		}
+		j.pointers[addr] = true
+		defer delete(j.pointers, addr)
+	}
+
+	switch kind {
+	case reflect.Bool:
+		printBool(j.w, v.Bool())
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		printInt(j.w, v.Int(), 10)
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uint, reflect.Uintptr:
+		printUint(j.w, v.Uint(), 10)
+
+	case reflect.Float32:
+		j.writeFloat(v.Float(), 32)
+
+	case reflect.Float64:
+		j.writeFloat(v.Float(), 64)
+
+	case reflect.Complex64:
+		j.writeAsString(func(w io.Writer) { printComplex(w, v.Complex(), 32, nil) })
+
+	case reflect.Complex128:
+		j.writeAsString(func(w io.Writer) { printComplex(w, v.Complex(), 64, nil) })
+
+	case reflect.String:
+		writeJSONString(j.w, v.String())
+
+	case reflect.Slice:
+		if v.IsNil() {
+			j.w.Write(nullBytes)
+			break
+		}
+		fallthrough
+
+	case reflect.Array:
+		j.depth++
+		if j.cs.depthExceeded(j.depth) {
+			j.w.Write(maxDepthJSONBytes)
+		} else {
+			j.w.Write(openBracketBytes)
+			numEntries := v.Len()
+			var indices []int
+			if j.cs.OmitFunc != nil {
+				indices = shownIndices(j.cs, j.path, v)
+				numEntries = len(indices)
+			}
+			for n := 0; n < numEntries; n++ {
+				if n > 0 {
+					j.w.Write(commaBytes)
+				}
+				i := n
+				if indices != nil {
+					i = indices[n]
+				}
+				if j.tracksPaths() {
+					j.dumpPath(indexPath(j.path, i), v.Index(i))
+				} else {
+					j.dump(v.Index(i))
+				}
+			}
+			j.w.Write(closeBracketBytes)
+		}
+		j.depth--
+
+	case reflect.Map:
+		if v.IsNil() {
+			j.w.Write(nullBytes)
+			break
+		}
+
+		j.depth++
+		if j.cs.depthExceeded(j.depth) {
+			j.w.Write(maxDepthJSONBytes)
+		} else {
+			j.w.Write(openBraceBytes)
+			keys := v.MapKeys()
+			if j.cs.OmitZero {
+				keys = nonZeroKeys(v, keys)
+			}
+			if j.cs.OmitFunc != nil {
+				keys = shownKeys(j.cs, j.path, v, keys)
+			}
+			if j.cs.SortKeys {
+				sortValues(keys, j.cs)
+			}
+			for i, key := range keys {
+				if i > 0 {
+					j.w.Write(commaBytes)
+				}
+				writeJSONString(j.w, j.mapKey(key))
+				j.w.Write(colonBytes)
+				if j.tracksPaths() {
+					j.dumpPath(keyPath(j.cs, j.path, key), v.MapIndex(key))
+				} else {
+					j.dump(v.MapIndex(key))
+				}
+			}
+			j.w.Write(closeBraceBytes)
+		}
+		j.depth--
+
+	case reflect.Struct:
+		var buf bytes.Buffer
+		if handleKnownTypes(j.cs, &buf, v) {
+			writeJSONString(j.w, buf.String())
+			break
+		}
+
+		j.depth++
+		if j.cs.depthExceeded(j.depth) {
+			j.w.Write(maxDepthJSONBytes)
+		} else {
+			j.w.Write(openBraceBytes)
+			vt := v.Type()
+			fields := visibleFields(j.cs, vt)
+			if j.cs.OmitZero {
+				fields = nonZeroFields(v, fields)
+			}
+			if j.cs.OmitFunc != nil {
+				fields = shownFields(j.cs, j.path, v, fields)
+			}
+			for i, fi := range fields {
+				if i > 0 {
+					j.w.Write(commaBytes)
+				}
+				name := fieldName(vt.Field(fi))
+				writeJSONString(j.w, name)
+				j.w.Write(colonBytes)
+				if j.tracksPaths() {
+					j.dumpPath(fieldPath(j.path, name), v.Field(fi))
+				} else {
+					j.dump(v.Field(fi))
+				}
+			}
+			j.w.Write(closeBraceBytes)
+		}
+		j.depth--
+
+	case reflect.UnsafePointer, reflect.Chan, reflect.Func:
+		if v.IsNil() {
+			j.w.Write(nullBytes)
+			break
+		}
+		j.writeAsString(func(w io.Writer) { printHexPtr(w, v.Pointer()) })
+
+	// There were not any other types at the time this code was written, but
+	// fall back to letting the default fmt package handle it in case any new
+	// types are added.
+	default:
+		j.writeAsString(func(w io.Writer) {
+			if v.CanInterface() {
+				fmt.Fprintf(w, "%v", v.Interface())
+			} else {
+				fmt.Fprintf(w, "%v", v.String())
+			}
+		})
+	}
+}
+
+// dumpArg outputs the passed top-level argument as JSON.  Like the dumpArg
+// method of dumpState, any panic which occurs while traversing it is
+// recovered.  Since the partial output of the argument wouldn't be valid JSON,
+// the argument is buffered and the panic information is output as a JSON
+// string in place of the entire argument instead.
+func (j *jsonState) dumpArg(arg interface{}) {
+	w := j.w
+	var buf bytes.Buffer
+	j.w = &buf
+	func() {
+		defer func() {
+			if err := recover(); err != nil {
+				buf.Reset()
+				writeJSONString(&buf, fmt.Sprintf("%s%v%s", panicBytes,
+					err, closeParenBytes))
+			}
+		}()
+		j.dumpPath("", reflect.ValueOf(arg))
+	}()
+	j.w = w
+	buf.WriteTo(w)
+}
+
+// fjdump is a helper function to consolidate the logic for producing JSON
+// output from the various public dump methods when the JSON option is set.
+// Each argument is output as a single line of JSON.
+func fjdump(cs *ConfigState, w io.Writer, a ...interface{}) {
+	for _, arg := range a {
+		j := jsonState{w: w, cs: cs}
+		j.pointers = make(map[uintptr]bool)
+		j.dumpArg(arg)
+		j.w.Write(newlineBytes)
+	}
+}
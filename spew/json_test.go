@@ -0,0 +1,169 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// jsonTest is used to describe a test to be performed against the JSON output
+// mode of the Dump methods.
+type jsonTest struct {
+	cs   *spew.ConfigState
+	in   interface{}
+	want string
+}
+
+// jsonCircular is used to test that circular references are output as a
+// reference object.
+type jsonCircular struct {
+	Name string
+	Next *jsonCircular
+}
+
+// TestJSON executes all of the tests described by jsonTests.
+func TestJSON(t *testing.T) {
+	scsJSON := &spew.ConfigState{JSON: true, SortKeys: true}
+	scsJSONNoMethods := &spew.ConfigState{JSON: true, DisableMethods: true}
+	scsJSONMaxDepth := &spew.ConfigState{JSON: true, MaxDepth: 1}
+	scsJSONOmitZero := &spew.ConfigState{JSON: true, OmitZero: true, SortKeys: true}
+	scsJSONRedact := &spew.ConfigState{JSON: true,
+		Redact: func(path string, v reflect.Value) (string, bool) {
+			return path, path == ".Password" || path == ".Keys[1]"
+		}}
+	scsJSONOmitFunc := &spew.ConfigState{JSON: true,
+		OmitFunc: func(path string, v reflect.Value) bool {
+			return path == ".Password" || path == ".Keys[0]"
+		}}
+	scsJSONFormatter := &spew.ConfigState{JSON: true}
+	scsJSONFormatter.RegisterFormatter(reflect.TypeOf(0),
+		func(w io.Writer, v reflect.Value, cfg *spew.ConfigState) {
+			fmt.Fprintf(w, "#%d", v.Int())
+		})
+
+	var nilPtr *int
+	var nilSlice []int
+	var nilMap map[string]int
+	s1 := struct {
+		a int
+		B string
+	}{1, "two"}
+	m := map[string]int{"one": 1, "two": 2, "three": 3}
+	im := map[int]string{2: "b", 1: "a"}
+	nested := [][]int{{1}, {2}}
+
+	tests := []jsonTest{
+		{scsJSON, nil, "null"},
+		{scsJSON, true, "true"},
+		{scsJSON, int8(-5), "-5"},
+		{scsJSON, uint64(18446744073709551615), "18446744073709551615"},
+		{scsJSON, float32(1.5), "1.5"},
+		{scsJSON, math.NaN(), `"NaN"`},
+		{scsJSON, math.Inf(-1), `"-Inf"`},
+		{scsJSON, complex(1, 2), `"(1+2i)"`},
+		{scsJSON, "a \"quoted\"\n\tstring\x01", `"a \"quoted\"\n\tstring\u0001"`},
+		{scsJSON, nilPtr, "null"},
+		{scsJSON, nilSlice, "null"},
+		{scsJSON, nilMap, "null"},
+		{scsJSON, []int{1, 2, 3}, "[1,2,3]"},
+		{scsJSON, [2]string{"a", "b"}, `["a","b"]`},
+		{scsJSON, []byte{1, 2}, "[1,2]"},
+		{scsJSON, s1, `{"a":1,"B":"two"}`},
+		{scsJSON, &s1, `{"a":1,"B":"two"}`},
+		{scsJSON, m, `{"one":1,"three":3,"two":2}`},
+		{scsJSON, im, `{"1":"a","2":"b"}`},
+		{scsJSON, tagSkip{1, "pw", 2}, `{"A":1,"B":2}`},
+		{scsJSON, stringer("test"), `"stringer test"`},
+		{scsJSONNoMethods, stringer("test"), `"test"`},
+		{scsJSONRedact, redactReq{User: "bob", Password: "pw", Keys: []int{1, 2}},
+			`{"User":"bob","Password":".Password","Tokens":null,"Keys":[1,".Keys[1]"]}`},
+		{scsJSONOmitFunc, redactReq{User: "bob", Password: "pw", Keys: []int{1, 2}},
+			`{"User":"bob","Tokens":null,"Keys":[2]}`},
+		{scsJSONOmitFunc, map[string]int{".Password": 1}, `{".Password":1}`},
+		{scsJSONFormatter, []int{1, 2}, `["#1","#2"]`},
+		{scsJSON, ringDumper{Items: []int{1, 2}}, `"ring of 2, head 0"`},
+		{scsJSONOmitZero, tagSkip{0, "pw", 2}, `{"B":2}`},
+		{scsJSONOmitZero, map[string]int{"a": 0, "b": 1}, `{"b":1}`},
+		{scsJSONMaxDepth, nested, `["<max depth reached>","<max depth reached>"]`},
+	}
+
+	for i, test := range tests {
+		got := strings.TrimSuffix(test.cs.Sdump(test.in), "\n")
+		if got != test.want {
+			t.Errorf("JSON #%d\n got: %s want: %s", i, got, test.want)
+			continue
+		}
+		var v interface{}
+		if err := json.Unmarshal([]byte(got), &v); err != nil {
+			t.Errorf("JSON #%d produced invalid JSON: %s (%v)", i, got, err)
+		}
+	}
+}
+
+// TestJSONCircularSlice ensures slices and maps which contain themselves are
+// detected and output as a reference to the address of their data.
+func TestJSONCircularSlice(t *testing.T) {
+	cs := &spew.ConfigState{JSON: true}
+	s := make([]interface{}, 1)
+	s[0] = s
+	got := strings.TrimSuffix(cs.Sdump(s), "\n")
+	want := fmt.Sprintf(`[{"$ref":"%p"}]`, s)
+	if got != want {
+		t.Errorf("JSON circular slice\n got: %s want: %s", got, want)
+	}
+
+	m := map[string]interface{}{}
+	m["self"] = m
+	got = strings.TrimSuffix(cs.Sdump(m), "\n")
+	want = fmt.Sprintf(`{"self":{"$ref":"%p"}}`, m)
+	if got != want {
+		t.Errorf("JSON circular map\n got: %s want: %s", got, want)
+	}
+}
+
+// TestJSONCircular ensures circular references are detected and output as a
+// reference to the address of the pointer which was already being dumped.
+func TestJSONCircular(t *testing.T) {
+	cs := &spew.ConfigState{JSON: true}
+	c := &jsonCircular{Name: "loop"}
+	c.Next = c
+
+	got := strings.TrimSuffix(cs.Sdump(c), "\n")
+	want := fmt.Sprintf(`{"Name":"loop","Next":{"$ref":"%p"}}`, c)
+	if got != want {
+		t.Errorf("JSON circular\n got: %s want: %s", got, want)
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(got), &v); err != nil {
+		t.Errorf("JSON circular produced invalid JSON: %s (%v)", got, err)
+	}
+
+	// Ensure each argument is output on its own line.
+	got = cs.Sdump(1, "two")
+	if got != "1\n\"two\"\n" {
+		t.Errorf("JSON multiple arguments\n got: %q want: %q", got,
+			"1\n\"two\"\n")
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// NOTE: Due to the following build constraints, this file will only be compiled
+// with versions of Go prior to 1.9 since sync.Map does not exist there.
+// +build !go1.9
+
+package spew
+
+import "reflect"
+
+// syncMapType is nil since sync.Map does not exist in this version of Go, so
+// no value will ever be detected as a sync.Map.
+var syncMapType reflect.Type
+
+// syncMapContents is a stub version which always reports the value can't be
+// accessed since sync.Map does not exist in this version of Go.
+func syncMapContents(v reflect.Value) (reflect.Value, bool) {
+	return reflect.Value{}, false
+}
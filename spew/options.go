@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+// Option is a function which configures a ConfigState.  Options are passed to
+// NewConfig.
+type Option func(*ConfigState)
+
+// NewConfig returns a ConfigState with the default settings described by
+// NewDefaultConfig after applying each of the passed options in order.  For
+// example:
+//
+//	spew.NewConfig(spew.WithIndent("\t"), spew.WithSortKeys()).Sdump(v)
+func NewConfig(opts ...Option) *ConfigState {
+	cs := NewDefaultConfig()
+	for _, opt := range opts {
+		opt(cs)
+	}
+	return cs
+}
+
+// WithIndent returns an Option which sets the Indent option to indent.
+func WithIndent(indent string) Option {
+	return func(cs *ConfigState) {
+		cs.Indent = indent
+	}
+}
+
+// WithIndentWidth returns an Option which sets the IndentWidth option to width
+// and clears the Indent option so it takes effect.
+func WithIndentWidth(width int) Option {
+	return func(cs *ConfigState) {
+		cs.Indent = ""
+		cs.IndentWidth = width
+	}
+}
+
+// WithMaxDepth returns an Option which sets the MaxDepth option to depth.
+func WithMaxDepth(depth int) Option {
+	return func(cs *ConfigState) {
+		cs.MaxDepth = depth
+	}
+}
+
+// WithMaxStringLength returns an Option which sets the MaxStringLength option
+// to max.
+func WithMaxStringLength(max int) Option {
+	return func(cs *ConfigState) {
+		cs.MaxStringLength = max
+	}
+}
+
+// WithMaxSliceElements returns an Option which sets the MaxSliceElements
+// option to max.
+func WithMaxSliceElements(max int) Option {
+	return func(cs *ConfigState) {
+		cs.MaxSliceElements = max
+	}
+}
+
+// WithMaxArrayBytes returns an Option which sets the MaxArrayBytes option to
+// max.
+func WithMaxArrayBytes(max int) Option {
+	return func(cs *ConfigState) {
+		cs.MaxArrayBytes = max
+	}
+}
+
+// WithMaxMapEntries returns an Option which sets the MaxMapEntries option to
+// max.
+func WithMaxMapEntries(max int) Option {
+	return func(cs *ConfigState) {
+		cs.MaxMapEntries = max
+	}
+}
+
+// WithMaxStructFields returns an Option which sets the MaxStructFields option
+// to max.
+func WithMaxStructFields(max int) Option {
+	return func(cs *ConfigState) {
+		cs.MaxStructFields = max
+	}
+}
+
+// WithDisableMethods returns an Option which sets the DisableMethods option.
+func WithDisableMethods() Option {
+	return func(cs *ConfigState) {
+		cs.DisableMethods = true
+	}
+}
+
+// WithDisablePointerMethods returns an Option which sets the
+// DisablePointerMethods option.
+func WithDisablePointerMethods() Option {
+	return func(cs *ConfigState) {
+		cs.DisablePointerMethods = true
+	}
+}
+
+// WithDisablePointerAddresses returns an Option which sets the
+// DisablePointerAddresses option.
+func WithDisablePointerAddresses() Option {
+	return func(cs *ConfigState) {
+		cs.DisablePointerAddresses = true
+	}
+}
+
+// WithDisableCapacities returns an Option which sets the DisableCapacities
+// option.
+func WithDisableCapacities() Option {
+	return func(cs *ConfigState) {
+		cs.DisableCapacities = true
+	}
+}
+
+// WithDisableLengths returns an Option which sets the DisableLengths option.
+func WithDisableLengths() Option {
+	return func(cs *ConfigState) {
+		cs.DisableLengths = true
+	}
+}
+
+// WithBytesAsString returns an Option which sets the BytesAsString option.
+func WithBytesAsString() Option {
+	return func(cs *ConfigState) {
+		cs.BytesAsString = true
+	}
+}
+
+// WithRunesAsString returns an Option which sets the RunesAsString option.
+func WithRunesAsString() Option {
+	return func(cs *ConfigState) {
+		cs.RunesAsString = true
+	}
+}
+
+// WithContinueOnMethod returns an Option which sets the ContinueOnMethod
+// option.
+func WithContinueOnMethod() Option {
+	return func(cs *ConfigState) {
+		cs.ContinueOnMethod = true
+	}
+}
+
+// WithDedupPointers returns an Option which sets the DedupPointers option.
+func WithDedupPointers() Option {
+	return func(cs *ConfigState) {
+		cs.DedupPointers = true
+	}
+}
+
+// WithCompact returns an Option which sets the Compact option.
+func WithCompact() Option {
+	return func(cs *ConfigState) {
+		cs.Compact = true
+	}
+}
+
+// WithColors returns an Option which sets the EnableColors option and uses the
+// passed colors.  Passing nil uses DefaultColors.
+func WithColors(colors *Colors) Option {
+	return func(cs *ConfigState) {
+		cs.EnableColors = true
+		cs.Colors = colors
+	}
+}
+
+// WithJSON returns an Option which sets the JSON option.
+func WithJSON() Option {
+	return func(cs *ConfigState) {
+		cs.JSON = true
+	}
+}
+
+// WithSortKeys returns an Option which sets the SortKeys option.
+func WithSortKeys() Option {
+	return func(cs *ConfigState) {
+		cs.SortKeys = true
+	}
+}
+
+// WithSpewKeys returns an Option which sets the SpewKeys option.
+func WithSpewKeys() Option {
+	return func(cs *ConfigState) {
+		cs.SpewKeys = true
+	}
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// splitPath splits the passed path into its segments, each of which is either
+// a field of the form .Name or an index or map key of the form [idx].
+// Brackets and quoted strings within map keys are balanced, so keys such as
+// arrays and strings containing brackets remain a single segment.  The boolean
+// return is false when the path is malformed.
+func splitPath(path string) ([]string, bool) {
+	var segments []string
+	for len(path) > 0 {
+		end := 1
+		switch path[0] {
+		case '.':
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			if end == 1 {
+				return nil, false
+			}
+
+		case '[':
+			depth, quoted := 1, false
+			for end < len(path) && depth > 0 {
+				c := path[end]
+				end++
+				switch {
+				case quoted && c == '\\':
+					end++
+				case c == '"':
+					quoted = !quoted
+				case quoted:
+				case c == '[':
+					depth++
+				case c == ']':
+					depth--
+				}
+			}
+			if depth > 0 || end > len(path) {
+				return nil, false
+			}
+
+		default:
+			return nil, false
+		}
+		segments = append(segments, path[:end])
+		path = path[end:]
+	}
+	return segments, true
+}
+
+// querySegment returns the field, element, or map value of the passed value
+// which the passed path segment addresses.  Fields are matched by the name
+// which is displayed for them and map keys by the same form they have in
+// paths.  The boolean return is false when there is no such value.
+func querySegment(cs *ConfigState, v reflect.Value, segment string) (reflect.Value, bool) {
+	if segment[0] == '.' {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		vt := v.Type()
+		for _, fi := range visibleFields(cs, vt) {
+			if fieldName(vt.Field(fi)) == segment[1:] {
+				return v.Field(fi), true
+			}
+		}
+		return reflect.Value{}, false
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		i, err := strconv.Atoi(segment[1 : len(segment)-1])
+		if err != nil || i < 0 || i >= v.Len() {
+			return reflect.Value{}, false
+		}
+		return v.Index(i), true
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			if keyPath(cs, "", key) == segment {
+				return v.MapIndex(key), true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// fquery is a helper function to consolidate the logic from the various public
+// query methods which take varying config states.
+func fquery(cs *ConfigState, v interface{}, path string) (interface{}, bool) {
+	segments, ok := splitPath(path)
+	if !ok {
+		return nil, false
+	}
+
+	cs = cs.snapshot()
+	rv := reflect.ValueOf(v)
+	for _, segment := range segments {
+		// Indirect through pointers and interfaces to the value the segment
+		// applies to.
+		for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+			if rv.IsNil() {
+				return nil, false
+			}
+			rv = rv.Elem()
+		}
+		if rv, ok = querySegment(cs, rv, segment); !ok {
+			return nil, false
+		}
+	}
+	if !rv.IsValid() {
+		return nil, true
+	}
+
+	// Values obtained from unexported struct fields can only be converted to
+	// interfaces by using unsafe, when it's available, to bypass the
+	// visibility rules.
+	if !rv.CanInterface() {
+		if UnsafeDisabled {
+			return nil, false
+		}
+		rv = unsafeReflectValue(rv)
+		if !rv.CanInterface() {
+			return nil, false
+		}
+	}
+	return rv.Interface(), true
+}
+
+// Query returns the value located at the passed path within the passed value,
+// such as .Items[2].Name or .Labels["env"], using the same form as the paths
+// passed to Walk and Redact.  Pointers and interfaces along the way are
+// followed and the value at an empty path is the passed value itself.  Values
+// in unexported struct fields are returned as well when unsafe is available.
+// The boolean return is false when the path is malformed or there is no value
+// at it, such as when it addresses a missing field or map key, an index which
+// is out of range, or the value behind a nil pointer.
+func Query(v interface{}, path string) (interface{}, bool) {
+	return fquery(&Config, v, path)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// queryItem is used to test querying nested and unexported values.
+type queryItem struct {
+	Name   string
+	Labels map[string]string
+	Next   *queryItem
+	Tagged int `spew:"tagged"`
+	secret string
+}
+
+// TestQuery ensures Query returns the value at the passed path.
+func TestQuery(t *testing.T) {
+	next := &queryItem{Name: "next"}
+	root := struct {
+		Items  []interface{}
+		Counts map[int]int
+		Grid   map[[2]int]string
+		Item   queryItem
+	}{
+		Items: []interface{}{1, "two", &queryItem{
+			Name:   "three",
+			Labels: map[string]string{"env": "prod", `a"]b`: "odd"},
+			Next:   next,
+			Tagged: 4,
+			secret: "hidden",
+		}},
+		Counts: map[int]int{7: 49},
+		Grid:   map[[2]int]string{{1, 2}: "cell"},
+	}
+
+	tests := []struct {
+		path string
+		want interface{}
+		ok   bool
+	}{
+		{".Items[1]", "two", true},
+		{".Items[2].Name", "three", true},
+		{`.Items[2].Labels["env"]`, "prod", true},
+		{`.Items[2].Labels["a\"]b"]`, "odd", true},
+		{".Items[2].Next", next, true},
+		{".Items[2].Next.Name", "next", true},
+		{".Items[2].tagged", 4, true},
+		{".Counts[7]", 49, true},
+		{".Grid[[1 2]]", "cell", true},
+		{".Item.Next", (*queryItem)(nil), true},
+		{".Item.Next.Name", nil, false},
+		{".Items[3]", nil, false},
+		{".Items[-1]", nil, false},
+		{".Items[x]", nil, false},
+		{".Items[2].Tagged", nil, false},
+		{`.Items[2].Labels["dev"]`, nil, false},
+		{".Counts[8]", nil, false},
+		{".Missing", nil, false},
+		{".Counts.Name", nil, false},
+		{"Items", nil, false},
+		{".Items[1", nil, false},
+		{"..Items", nil, false},
+	}
+
+	for i, test := range tests {
+		got, ok := spew.Query(&root, test.path)
+		if ok != test.ok || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Query #%d %q: got %v, %v want %v, %v", i, test.path,
+				got, ok, test.want, test.ok)
+		}
+	}
+
+	// Ensure the value itself is returned for an empty path, including nil.
+	if got, ok := spew.Query(5, ""); !ok || got != 5 {
+		t.Errorf("Query empty path: got %v, %v want 5, true", got, ok)
+	}
+	if got, ok := spew.Query(nil, ""); !ok || got != nil {
+		t.Errorf("Query nil: got %v, %v want <nil>, true", got, ok)
+	}
+
+	// Ensure values in unexported fields are returned when unsafe is
+	// available.
+	got, ok := spew.Query(root, ".Items[2].secret")
+	if spew.UnsafeDisabled {
+		if ok {
+			t.Errorf("Query unexported: got %v, want not found", got)
+		}
+	} else if !ok || got != "hidden" {
+		t.Errorf("Query unexported: got %v, %v want hidden, true", got, ok)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// NOTE: Due to the following build constraints, this file will only be compiled
+// with Go 1.21 and later since that is when log/slog was introduced.
+// +build go1.21
+
+package spew
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+)
+
+// logValuer implements the slog.LogValuer interface for a value so that it is
+// resolved according to the config state when it is logged.
+type logValuer struct {
+	cs *ConfigState
+	v  interface{}
+}
+
+// LogValue returns the value as a group of attributes for its struct fields.
+// It implements the slog.LogValuer interface.
+func (l logValuer) LogValue() slog.Value {
+	ls := logState{cs: l.cs, leafCS: *l.cs}
+	ls.pointers = make(map[uintptr]bool)
+
+	// Values other than structs are rendered the same as a compact Dump.
+	ls.leafCS.Compact = true
+	ls.leafCS.JSON = false
+	ls.leafCS.GoSyntax = false
+	ls.leafCS.EnableColors = false
+
+	return ls.value(reflect.ValueOf(l.v))
+}
+
+// logState contains information about the state of converting a value to a
+// slog.Value.
+type logState struct {
+	depth    int
+	pointers map[uintptr]bool
+	cs       *ConfigState
+	leafCS   ConfigState
+}
+
+// leaf returns the passed value rendered as a string the same as a compact
+// Dump.
+func (ls *logState) leaf(v reflect.Value) slog.Value {
+	var buf bytes.Buffer
+	d := dumpState{w: &buf, cs: &ls.leafCS}
+	d.pointers = make(map[uintptr]int)
+	d.dump(v)
+	return slog.StringValue(buf.String())
+}
+
+// value converts the passed value to a slog.Value.  Structs become groups with
+// an attribute for each field, including unexported fields, while basic types
+// become the equivalent slog kind and everything else is rendered as a string.
+// Pointers and interfaces are followed to the values they reference, however
+// circular references are output the same as Dump outputs them.
+func (ls *logState) value(v reflect.Value) slog.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ls.leaf(v)
+		}
+		if v.Kind() == reflect.Ptr {
+			addr := v.Pointer()
+			if ls.pointers[addr] {
+				return slog.StringValue(string(ls.cs.circularMarker(circularBytes)))
+			}
+			ls.pointers[addr] = true
+			defer delete(ls.pointers, addr)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ls.leaf(v)
+	}
+
+	// Values which display themselves via error or Stringer interfaces, as
+	// well as types with a readable form, are rendered as strings.
+	if !ls.cs.DisableMethods {
+		var buf bytes.Buffer
+		if handleMethods(ls.cs, &buf, v) {
+			return slog.StringValue(buf.String())
+		}
+	}
+	var buf bytes.Buffer
+	if handleKnownTypes(ls.cs, &buf, v) {
+		return slog.StringValue(buf.String())
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return slog.BoolValue(v.Bool())
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return slog.Int64Value(v.Int())
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uint, reflect.Uintptr:
+		return slog.Uint64Value(v.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		return slog.Float64Value(v.Float())
+
+	case reflect.String:
+		return slog.StringValue(v.String())
+
+	case reflect.Struct:
+		ls.depth++
+		defer func() { ls.depth-- }()
+		if ls.cs.depthExceeded(ls.depth) {
+			return slog.StringValue(string(maxDepthBytes))
+		}
+
+		vt := v.Type()
+		fields := visibleFields(ls.cs, vt)
+		if ls.cs.OmitZero {
+			fields = nonZeroFields(v, fields)
+		}
+		attrs := make([]slog.Attr, 0, len(fields))
+		for _, fi := range fields {
+			attrs = append(attrs, slog.Attr{
+				Key:   fieldName(vt.Field(fi)),
+				Value: ls.value(v.Field(fi)),
+			})
+		}
+		return slog.GroupValue(attrs...)
+	}
+
+	return ls.leaf(v)
+}
+
+// LogValue returns a slog.LogValuer for the passed value which logs structs as
+// a group of attributes for their fields, including unexported fields, rather
+// than as a single opaque string.  Nested structs become nested groups while
+// other values are logged as the equivalent slog kind or, when there is none,
+// rendered the same as a compact Dump.  For example:
+//
+//	slog.Info("state", "obj", spew.LogValue(obj))
+func LogValue(v interface{}) slog.LogValuer {
+	return logValuer{cs: Config.snapshot(), v: v}
+}
+
+// LogValue returns a slog.LogValuer for the passed value according to the
+// config state.  See the top-level LogValue function for details.
+func (c *ConfigState) LogValue(v interface{}) slog.LogValuer {
+	return logValuer{cs: c.snapshot(), v: v}
+}
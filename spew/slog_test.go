@@ -0,0 +1,89 @@
+// Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// NOTE: Due to the following build constraints, this file will only be compiled
+// with Go 1.21 and later since that is when log/slog was introduced.
+// +build go1.21
+
+package spew_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// logInner and logOuter are used to test logging nested structs with
+// unexported fields.
+type logInner struct {
+	Count int
+	ratio float64
+}
+type logOuter struct {
+	Name   string
+	inner  logInner
+	Tags   []string
+	Err    error
+	Secret string `spew:"-"`
+	Self   *logOuter
+}
+
+// TestLogValue ensures values are logged as groups of attributes for their
+// struct fields.
+func TestLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	v := &logOuter{
+		Name:   "test",
+		inner:  logInner{Count: 2, ratio: 0.5},
+		Tags:   []string{"a"},
+		Err:    customError(1),
+		Secret: "pw",
+	}
+	v.Self = v
+
+	tests := []struct {
+		val  slog.LogValuer
+		want string
+	}{
+		{spew.LogValue(v), "level=INFO msg=state obj.Name=test " +
+			"obj.inner.Count=2 obj.inner.ratio=0.5 " +
+			`obj.Tags="([]string)(len=1 cap=1){(string)(len=1)\"a\"}" ` +
+			"obj.Err=\"error: 1\" obj.Self=\"<already shown>\"\n"},
+		{spew.LogValue(5), "level=INFO msg=state obj=5\n"},
+		{spew.LogValue(stringer("x")), "level=INFO msg=state obj=\"stringer x\"\n"},
+		{(&spew.ConfigState{MaxDepth: 1}).LogValue(v),
+			"level=INFO msg=state obj.Name=test obj.inner=\"<max depth reached>\" " +
+				`obj.Tags="([]string)(len=1 cap=1){(string)(len=1)\"a\"}" ` +
+				"obj.Err=\"error: 1\" obj.Self=\"<already shown>\"\n"},
+	}
+
+	for i, test := range tests {
+		buf.Reset()
+		logger.Info("state", "obj", test.val)
+		if got := buf.String(); got != test.want {
+			t.Errorf("LogValue #%d\n got: %s want: %s", i, got, test.want)
+		}
+	}
+}
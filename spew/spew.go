@@ -140,9 +140,10 @@ func Sprintln(a ...interface{}) string {
 // convertArgs accepts a slice of arguments and returns a slice of the same
 // length with each argument converted to a default spew Formatter interface.
 func convertArgs(args []interface{}) (formatters []interface{}) {
+	cs := Config.snapshot()
 	formatters = make([]interface{}, len(args))
 	for index, arg := range args {
-		formatters[index] = NewFormatter(arg)
+		formatters[index] = newFormatter(cs, arg)
 	}
 	return formatters
 }
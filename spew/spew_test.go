@@ -20,8 +20,13 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"math"
+	"math/big"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 )
@@ -38,6 +43,7 @@ const (
 	fCSPrint
 	fCSPrintln
 	fCSSdump
+	fCSDumpb
 	fCSSprint
 	fCSSprintf
 	fCSSprintln
@@ -49,6 +55,7 @@ const (
 	fPrint
 	fPrintln
 	fSdump
+	fDumpb
 	fSprint
 	fSprintf
 	fSprintln
@@ -61,6 +68,7 @@ var spewFuncStrings = map[spewFunc]string{
 	fCSFprintf:      "ConfigState.Fprintf",
 	fCSFprintln:     "ConfigState.Fprintln",
 	fCSSdump:        "ConfigState.Sdump",
+	fCSDumpb:        "ConfigState.Dumpb",
 	fCSPrint:        "ConfigState.Print",
 	fCSPrintln:      "ConfigState.Println",
 	fCSSprint:       "ConfigState.Sprint",
@@ -74,6 +82,7 @@ var spewFuncStrings = map[spewFunc]string{
 	fPrint:          "spew.Print",
 	fPrintln:        "spew.Println",
 	fSdump:          "spew.Sdump",
+	fDumpb:          "spew.Dumpb",
 	fSprint:         "spew.Sprint",
 	fSprintf:        "spew.Sprintf",
 	fSprintln:       "spew.Sprintln",
@@ -127,16 +136,187 @@ func initSpewTests() {
 	// Config states with various settings.
 	scsDefault := spew.NewDefaultConfig()
 	scsNoMethods := &spew.ConfigState{Indent: " ", DisableMethods: true}
+	scsNoDumper := &spew.ConfigState{Indent: " ", DisableDumperInterface: true}
+	scsText := &spew.ConfigState{Indent: " ", UseTextMarshaler: true}
+	scsTags := &spew.ConfigState{Indent: " ", ShowFieldTags: true}
+	scsIface := &spew.ConfigState{Indent: " ", ShowInterfaceType: true,
+		DisablePointerAddresses: true}
+	scsTyped := &spew.ConfigState{Indent: " ", TypedLiterals: true,
+		DisablePointerAddresses: true}
+	scsTypedCont := &spew.ConfigState{Indent: " ", TypedLiterals: true,
+		ContinueOnMethod: true}
+	scsTypedRune := &spew.ConfigState{Indent: " ", TypedLiterals: true,
+		RuneAsChar: true}
+	scsMarkers := &spew.ConfigState{Indent: " ", NilString: "nil",
+		CircularString: "CYCLE"}
+	scsUnwrap := &spew.ConfigState{Indent: " ", UnwrapErrors: true}
+	scsUnwrapCont := &spew.ConfigState{Indent: " ", UnwrapErrors: true,
+		ContinueOnMethod: true}
+	scsTextCont := &spew.ConfigState{Indent: " ", UseTextMarshaler: true,
+		ContinueOnMethod: true}
+	scsTextNoMethods := &spew.ConfigState{Indent: " ", UseTextMarshaler: true,
+		DisableMethods: true}
+	scsNoTypeMethods := &spew.ConfigState{Indent: " ",
+		DisableMethodsForTypes: map[reflect.Type]bool{
+			reflect.TypeOf(stringer("")): true,
+		}}
 	scsNoPmethods := &spew.ConfigState{Indent: " ", DisablePointerMethods: true}
 	scsMaxDepth := &spew.ConfigState{Indent: " ", MaxDepth: 1}
 	scsContinue := &spew.ConfigState{Indent: " ", ContinueOnMethod: true}
 	scsNoPtrAddr := &spew.ConfigState{DisablePointerAddresses: true}
+	scsPrefix := &spew.ConfigState{Indent: " ", LinePrefix: "> "}
+	scsNoFollow := &spew.ConfigState{Indent: " ", DisablePointerFollowing: true}
+	scsNoFollowAddr := &spew.ConfigState{Indent: " ",
+		DisablePointerFollowing: true, DisablePointerAddresses: true}
 	scsNoCap := &spew.ConfigState{DisableCapacities: true}
+	scsNoLen := &spew.ConfigState{DisableLengths: true}
+	scsNoLenCap := spew.NewConfig(spew.WithIndent(""), spew.WithDisableLengths(),
+		spew.WithDisableCapacities())
+	lenCapCh := make(chan int, 3)
+	lenCapCh <- 1
+	scsMaxStr := &spew.ConfigState{Indent: " ", MaxStringLength: 5}
+	scsMaxSlice := &spew.ConfigState{Indent: " ", MaxSliceElements: 2}
+	scsMaxMap := &spew.ConfigState{Indent: " ", MaxMapEntries: 2, SortKeys: true}
+	scsMaxFields := &spew.ConfigState{Indent: " ", MaxStructFields: 1}
+	scsMaxFieldsSorted := spew.NewConfig(spew.WithMaxStructFields(2))
+	scsMaxFieldsSorted.SortFields = true
+	scsHeaderAddrs := &spew.ConfigState{Indent: " ", ShowHeaderAddresses: true}
+	headerMap := map[string]int{"a": 1}
+	headerSlice := []int{1}
+	scsCounts := &spew.ConfigState{Indent: " ", ShowCollectionCounts: true,
+		MaxMapEntries: 1, MaxSliceElements: 1, SortKeys: true,
+		DisableLengths: true, DisableCapacities: true}
+	scsBytesStr := &spew.ConfigState{Indent: " ", BytesAsString: true}
+	scsIndentWidth := spew.NewConfig(spew.WithIndentWidth(4))
+	scsIndentBoth := &spew.ConfigState{Indent: "\t", IndentWidth: 4}
+	scsNoQuote := &spew.ConfigState{Indent: " ", DisableStringQuoting: true}
+	scsExported := &spew.ConfigState{Indent: " ", ExportedOnly: true,
+		DisablePointerAddresses: true}
+	scsDirect := &spew.ConfigState{Indent: " ", OnlyDirectMethods: true,
+		DisablePointerAddresses: true}
+	wrapField := "stringer a"
+	if spew.UnsafeDisabled {
+		wrapField = `"a"`
+	}
+	scsMaxArrayBytes := spew.NewConfig(spew.WithMaxArrayBytes(3))
+	var hash [32]byte
+	for i := range hash {
+		hash[i] = byte(i)
+	}
+	scsRunesStr := &spew.ConfigState{Indent: " ", RunesAsString: true}
+	scsRawStr := &spew.ConfigState{Indent: " ", RawStrings: true,
+		RunesAsString: true}
+	scsDedup := &spew.ConfigState{Indent: " ", DedupPointers: true,
+		DisablePointerAddresses: true}
+	scsMaxOutput := &spew.ConfigState{Indent: " ", MaxOutputBytes: 20}
+	scsEmbedded := &spew.ConfigState{Indent: " ", MarkEmbedded: true,
+		DisablePointerAddresses: true}
+	scsAliases := &spew.ConfigState{Indent: " ", PointerAliases: true}
+	scsFloatSpecial := &spew.ConfigState{Indent: " ", FloatSpecialAsString: true}
+	scsPolar := &spew.ConfigState{Indent: " ", ComplexFormat: spew.ComplexPolar}
+	scsPolarPrec := &spew.ConfigState{Indent: " ", ComplexFormat: spew.ComplexPolar,
+		FloatPrecision: 3}
+	scsRectPrec := &spew.ConfigState{Indent: " ", FloatPrecision: 2}
+	scsKind := &spew.ConfigState{Indent: " ", ShowKind: true,
+		DisablePointerAddresses: true}
+	aliasInt := 5
+	aliasPtr := &aliasInt
+	scsColors := &spew.ConfigState{Indent: " ", EnableColors: true,
+		DisablePointerAddresses: true, Colors: &spew.Colors{Type: "<t>",
+			FieldName: "<f>", String: "<s>", Number: "<n>", Pointer: "<p>",
+			Nil: "<0>"}}
+	scsDefColors := &spew.ConfigState{EnableColors: true}
+	scsCompact := &spew.ConfigState{Indent: " ", Compact: true,
+		DisablePointerAddresses: true, MaxSliceElements: 3}
+	scsCompactDepth := &spew.ConfigState{Indent: " ", Compact: true, MaxDepth: 1}
+
+	scsMaxChain := &spew.ConfigState{Indent: " ", MaxPointerChain: 2}
+	scsRuneChar := &spew.ConfigState{Indent: " ", RuneAsChar: true}
+	scsHex := &spew.ConfigState{Indent: " ", IntegerBase: 16}
+	scsBinary := &spew.ConfigState{Indent: " ", IntegerBase: 2}
+	scsOctal := &spew.ConfigState{Indent: " ", IntegerBase: 8}
+	scsBase36 := &spew.ConfigState{Indent: " ", IntegerBase: 36}
+	scsFloat3f := &spew.ConfigState{Indent: " ", FloatFormat: 'f', FloatPrecision: 3}
+	scsFloatE := &spew.ConfigState{Indent: " ", FloatFormat: 'e'}
+	scsFloat0f := &spew.ConfigState{Indent: " ", FloatFormat: 'f', ZeroFloatPrecision: true}
+	scsFloat0 := &spew.ConfigState{Indent: " ", FloatFormat: 'f'}
+	scsSortFields := &spew.ConfigState{Indent: " ", SortFields: true}
+	type sortFields struct {
+		Zeta  int
+		alpha int
+		*embed
+		Beta int `spew:"aardvark"`
+	}
+	sf := sortFields{1, 2, &embed{"e"}, 3}
+	scsOmitZero := &spew.ConfigState{Indent: " ", OmitZero: true, SortKeys: true}
+	type omitZero struct {
+		A int
+		B string
+		C *int
+		D []int
+		E struct{ X, Y float64 }
+		F [2]bool
+		G interface{}
+		H string
+	}
+	oz := omitZero{H: "set", F: [2]bool{false, true}}
+	scsNoTimeFmt := &spew.ConfigState{Indent: " ", DisableMethods: true,
+		DisableTimeFormat: true}
+	bi, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	type bigHolder struct {
+		I big.Int
+		R *big.Rat
+		F *big.Float
+	}
+	bh := bigHolder{I: *bi, R: big.NewRat(3, 4), F: big.NewFloat(1.5)}
+	tm := time.Date(2009, time.November, 10, 23, 0, 0, 5, time.UTC)
+	type timeHolder struct {
+		T time.Time
+	}
+	scsRedact := &spew.ConfigState{Indent: " ", DisableCapacities: true,
+		Redact: func(path string, v reflect.Value) (string, bool) {
+			switch {
+			case strings.HasSuffix(path, ".Password"):
+				return "****", true
+			case strings.HasPrefix(path, ".Tokens["):
+				return "<" + path + ">", true
+			case strings.HasPrefix(path, ".Keys["):
+				return "<" + path + ">", v.Int() == 2
+			}
+			return "", false
+		}}
+	rr := redactReq{User: "bob", Password: "hunter2",
+		Tokens: map[string]string{"api": "secret"}, Keys: []int{1, 2}}
+	scsOmitFunc := &spew.ConfigState{Indent: " ", DisableCapacities: true,
+		OmitFunc: func(path string, v reflect.Value) bool {
+			switch {
+			case strings.HasSuffix(path, ".Password"):
+				return true
+			case path == "[2].Tokens[\"api\"]" || path == "[2].Keys[0]":
+				return true
+			}
+			return v.Kind() == reflect.Ptr && v.IsNil()
+		}}
+
+	// Variables for tests on chains of pointers.
+	ci := 5
+	cp1 := &ci
+	cp2 := &cp1
+	cp3 := &cp2
+
+	// Variables for tests on circular references.
+	xr := &xref1{}
+	xr.ps2 = &xref2{ps1: xr}
 
 	// Variables for tests on types which implement Stringer interface with and
 	// without a pointer receiver.
 	ts := stringer("test")
 	tps := pstringer("test")
+	cyc := make([]interface{}, 1)
+	cyc[0] = cyc
+	we := wrapError{Msg: "outer", Err: wrapError{Msg: "middle",
+		Err: customError(1)}}
+	tpsw := pstringerWrap{&tps}
 
 	type ptrTester struct {
 		s *struct{}
@@ -154,6 +334,10 @@ func initSpewTests() {
 	dt := depthTester{indirCir1{nil}, [1]string{"arr"}, []string{"slice"},
 		map[string]int{"one": 1}}
 
+	// Variable for tests on pointers which are shared between siblings.
+	ew := embedwrap{embed: &embed{a: "x"}}
+	ew.e = ew.embed
+
 	// Variable for tests on types which implement error interface.
 	te := customError(10)
 
@@ -165,6 +349,8 @@ func initSpewTests() {
 		{scsDefault, fCSPrint, "", int64(9223372036854775807), "9223372036854775807"},
 		{scsDefault, fCSPrintln, "", uint8(255), "255\n"},
 		{scsDefault, fCSSdump, "", uint8(64), "(uint8) 64\n"},
+		{scsDefault, fCSDumpb, "", uint8(64), "(uint8) 64\n"},
+		{scsDefault, fDumpb, "", []int{}, "([]int) {}\n"},
 		{scsDefault, fCSSprint, "", complex(1, 2), "(1+2i)"},
 		{scsDefault, fCSSprintf, "%v", complex(float32(3), 4), "(3+4i)"},
 		{scsDefault, fCSSprintln, "", complex(float64(5), 6), "(5+6i)\n"},
@@ -179,18 +365,121 @@ func initSpewTests() {
 		{scsDefault, fSprint, "", complex(-1, -2), "(-1-2i)"},
 		{scsDefault, fSprintf, "%v", complex(float32(-3), -4), "(-3-4i)"},
 		{scsDefault, fSprintln, "", complex(float64(-5), -6), "(-5-6i)\n"},
+		{scsDefault, fCSSdump, "", []int(nil), "([]int) <nil>\n"},
+		{scsDefault, fCSSdump, "", []int{}, "([]int) {}\n"},
+		{scsDefault, fCSSdump, "", map[string]int(nil), "(map[string]int) <nil>\n"},
+		{scsDefault, fCSSdump, "", map[string]int{}, "(map[string]int) {}\n"},
+		{scsDefault, fCSSprint, "", []int(nil), "<nil>"},
+		{scsDefault, fCSSprint, "", []int{}, "[]"},
+		{scsDefault, fCSSprint, "", map[string]int(nil), "<nil>"},
+		{scsDefault, fCSSprint, "", map[string]int{}, "map[]"},
+		{scsDefault, fCSSprintf, "%#v", []int(nil), "([]int)<nil>"},
+		{scsDefault, fCSSprintf, "%#v", []int{}, "([]int)[]"},
+		{scsDefault, fCSSdump, "", [0]int{}, "([0]int) {}\n"},
+		{scsDefault, fCSSdump, "", struct{}{}, "(struct {}) {}\n"},
+		{scsDefault, fCSSdump, "", []interface{}{[]int{}, map[int]int{}},
+			"([]interface {}) (len=2 cap=2) {\n ([]int) {},\n (map[int]int) {}\n}\n"},
+		{scsOmitZero, fCSSdump, "", omitZero{}, "(spew_test.omitZero) {}\n"},
+		{scsNoDumper, fCSSdump, "", ringDumper{Head: 1},
+			"(spew_test.ringDumper) ring\n"},
+		{scsNoMethods, fCSSdump, "", ringDumper{Head: 1},
+			"(spew_test.ringDumper) ring of 0, head 1\n"},
+		{scsDefault, fCSSdump, "", textMarshaler(1), "(spew_test.textMarshaler) 1\n"},
+		{scsText, fCSSdump, "", textMarshaler(1), "(spew_test.textMarshaler) text 1\n"},
+		{scsText, fCSSdump, "", textMarshaler(-1), "(spew_test.textMarshaler) -1\n"},
+		{scsText, fCSFprint, "", []textMarshaler{2, 3}, "[text 2 text 3]"},
+		{scsText, fCSSdump, "", time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC),
+			"(time.Time) 2009-11-10 23:00:00 +0000 UTC\n"},
+		{scsTextCont, fCSSdump, "", textMarshaler(1),
+			"(spew_test.textMarshaler) (text 1) 1\n"},
+		{scsTextNoMethods, fCSSdump, "", textMarshaler(1),
+			"(spew_test.textMarshaler) 1\n"},
+		{scsDefault, fCSSdump, "", we, "(spew_test.wrapError) outer\n"},
+		{scsUnwrap, fCSSdump, "", we,
+			"(spew_test.wrapError) outer -> middle -> error: 1\n"},
+		{scsUnwrap, fCSFprint, "", multiError{we, nil, customError(2)},
+			"multi -> [outer -> middle -> error: 1, error: 2]"},
+		{scsUnwrap, fCSFprint, "", wrapError{Msg: "alone"}, "alone"},
+		{scsUnwrapCont, fCSSdump, "", wrapError{Msg: "a", Err: customError(3)},
+			"(spew_test.wrapError) (a -> error: 3) {\n" +
+				" Msg: (string) (len=1) \"a\",\n" +
+				" Err: (spew_test.customError) (error: 3) 3\n}\n"},
+		{scsMarkers, fCSSdump, "", []int(nil), "([]int) nil\n"},
+		{scsMarkers, fCSSdump, "", nil, "(interface {}) nil\n"},
+		{scsMarkers, fCSSdump, "", (*int)(nil), "(*int)(nil)\n"},
+		{scsMarkers, fCSSdump, "", uintptr(0), "(uintptr) nil\n"},
+		{scsMarkers, fCSFprint, "", map[string]int(nil), "nil"},
+		{scsMarkers, fCSFprint, "", nil, "nil"},
+		{scsMarkers, fCSFprint, "", (*int)(nil), "nil"},
+		{scsMarkers, fCSSdump, "", cyc, "([]interface {}) (len=1 cap=1) {\n" +
+			" ([]interface {}) (len=1 cap=1) CYCLE\n}\n"},
+		{scsMarkers, fCSFprint, "", cyc, "[CYCLE]"},
+		{scsTags, fCSSdump, "", tagRename{UserID: 1},
+			"(spew_test.tagRename) {\n" +
+				" user_id `spew:\"user_id\"`: (int) 1,\n" +
+				" displayName `spew:\"displayName,omitempty\"`: (string) \"\",\n" +
+				" - `spew:\"-,\"`: (int) 0,\n" +
+				" Plain `spew:\",omitempty\"`: (int) 0\n}\n"},
+		{scsTags, fCSSdump, "", struct{ A int }{1},
+			"(struct { A int }) {\n A: (int) 1\n}\n"},
+		{scsIface, fCSSdump, "", struct {
+			A interface{}
+			E error
+			P fmt.Stringer
+			N interface{}
+		}{1, customError(2), &tps, nil},
+			"(struct { A interface {}; E error; P fmt.Stringer; N interface {} }) {\n" +
+				" A: (interface {} = int) 1,\n" +
+				" E: (error = spew_test.customError) error: 2,\n" +
+				" P: (fmt.Stringer = *spew_test.pstringer)((len=4) stringer test),\n" +
+				" N: (interface {}) <nil>\n}\n"},
+		{scsIface, fCSSdump, "", map[string]interface{}{"k": []int{}},
+			"(map[string]interface {}) (len=1) {\n" +
+				" (string) (len=1) \"k\": (interface {} = []int) {}\n}\n"},
+		{scsIface, fCSSdump, "", []int{1}, "([]int) (len=1 cap=1) {\n (int) 1\n}\n"},
+		{scsTyped, fCSSdump, "", uint8(255), "uint8(255)\n"},
+		{scsTyped, fCSSdump, "", int64(9223372036854775807),
+			"int64(9223372036854775807)\n"},
+		{scsTyped, fCSSdump, "", float32(3.14), "float32(3.14)\n"},
+		{scsTyped, fCSSdump, "", complex64(1 + 2i), "complex64(1+2i)\n"},
+		{scsTyped, fCSSdump, "", struct {
+			A uint16
+			B string
+		}{1, "x"}, "(struct { A uint16; B string }) {\n A: uint16(1),\n" +
+			" B: (string) (len=1) \"x\"\n}\n"},
+		{scsTyped, fCSSdump, "", &ci, "(*int)(int(5))\n"},
+		{scsTyped, fCSSdump, "", customError(7),
+			"(spew_test.customError) error: 7\n"},
+		{scsTypedCont, fCSSdump, "", customError(7),
+			"(error: 7) spew_test.customError(7)\n"},
+		{scsTypedRune, fCSSdump, "", 'a', "int32('a')\n"},
 		{scsNoMethods, fCSFprint, "", ts, "test"},
 		{scsNoMethods, fCSFprint, "", &ts, "<*>test"},
 		{scsNoMethods, fCSFprint, "", tps, "test"},
 		{scsNoMethods, fCSFprint, "", &tps, "<*>test"},
+		{scsNoTypeMethods, fCSFprint, "", ts, "test"},
+		{scsNoTypeMethods, fCSFprint, "", &ts, "<*>test"},
+		{scsNoTypeMethods, fCSSdump, "", ts, "(spew_test.stringer) (len=4) \"test\"\n"},
+		{scsNoTypeMethods, fCSFprint, "", &tps, "<*>stringer test"},
+		{scsNoTypeMethods, fCSSdump, "", te, "(spew_test.customError) error: 10\n"},
+		{scsDefault, fCSSdump, "", tpsw, "(spew_test.pstringerWrap) stringer test\n"},
+		{scsDefault, fCSFprint, "", tpsw, "stringer test"},
+		{scsDefault, fCSSdump, "", struct{ W pstringerWrap }{tpsw},
+			"(struct { W spew_test.pstringerWrap }) {\n W: (spew_test.pstringerWrap) stringer test\n}\n"},
 		{scsNoPmethods, fCSFprint, "", ts, "stringer test"},
 		{scsNoPmethods, fCSFprint, "", &ts, "<*>stringer test"},
 		{scsNoPmethods, fCSFprint, "", tps, "test"},
 		{scsNoPmethods, fCSFprint, "", &tps, "<*>stringer test"},
 		{scsMaxDepth, fCSFprint, "", dt, "{{<max>} [<max>] [<max>] map[<max>]}"},
+		{scsMaxDepth, fCSFprintf, "%+v", dt,
+			"{ic:{<max>} arr:[<max>] slice:[<max>] m:map[<max>]}"},
+		{scsMaxDepth, fCSSprintf, "%#v", [][]int{{1}}, "([][]int)[[<max>]]"},
+		{scsMaxDepth, fCSSprintf, "%#+v", &dt, fmt.Sprintf("(*spew_test.depthTester)(%p)"+
+			"{ic:(spew_test.indirCir1){<max>} arr:([1]string)[<max>] "+
+			"slice:([]string)[<max>] m:(map[string]int)map[<max>]}", &dt)},
 		{scsMaxDepth, fCSFdump, "", dt, "(spew_test.depthTester) {\n" +
 			" ic: (spew_test.indirCir1) {\n  <max depth reached>\n },\n" +
-			" arr: ([1]string) (len=1 cap=1) {\n  <max depth reached>\n },\n" +
+			" arr: ([1]string) (len=1) {\n  <max depth reached>\n },\n" +
 			" slice: ([]string) (len=1 cap=1) {\n  <max depth reached>\n },\n" +
 			" m: (map[string]int) (len=1) {\n  <max depth reached>\n }\n}\n"},
 		{scsContinue, fCSFprint, "", ts, "(stringer test) test"},
@@ -199,10 +488,293 @@ func initSpewTests() {
 		{scsContinue, fCSFprint, "", te, "(error: 10) 10"},
 		{scsContinue, fCSFdump, "", te, "(spew_test.customError) " +
 			"(error: 10) 10\n"},
+		{scsPrefix, fCSSdump, "", []int{1, 2}, "> ([]int) (len=2 cap=2) {\n" +
+			">  (int) 1,\n>  (int) 2\n> }\n"},
+		{scsPrefix, fCSSdump, "", []byte{1, 2}, "> ([]uint8) (len=2 cap=2) {\n" +
+			">  00000000  01 02                                             |..|\n" +
+			"> }\n"},
+		{scsNoFollow, fCSSdump, "", &ci, "(*int)(" + fmt.Sprintf("%p", &ci) + ")\n"},
+		{scsNoFollow, fCSSdump, "", cp2, "(**int)(" + fmt.Sprintf("%p", cp2) + ")\n"},
+		{scsNoFollow, fCSSdump, "", (*int)(nil), "(*int)(<nil>)\n"},
+		{scsNoFollow, fCSSdump, "", struct{ P *int }{&ci}, "(struct { P *int }) {\n" +
+			" P: (*int)(" + fmt.Sprintf("%p", &ci) + ")\n}\n"},
+		{scsNoFollowAddr, fCSSdump, "", tptr, "(*spew_test.ptrTester)\n"},
 		{scsNoPtrAddr, fCSFprint, "", tptr, "<*>{<*>{}}"},
-		{scsNoPtrAddr, fCSSdump, "", tptr, "(*spew_test.ptrTester)({\ns: (*struct {})({\n})\n})\n"},
-		{scsNoCap, fCSSdump, "", make([]string, 0, 10), "([]string) {\n}\n"},
+		{scsNoPtrAddr, fCSSdump, "", tptr, "(*spew_test.ptrTester)({\ns: (*struct {})({})\n})\n"},
+		{scsNoCap, fCSSdump, "", make([]string, 0, 10), "([]string) {}\n"},
 		{scsNoCap, fCSSdump, "", make([]string, 1, 10), "([]string) (len=1) {\n(string) \"\"\n}\n"},
+		{scsNoLen, fCSSdump, "", make([]string, 1, 10), "([]string) (cap=10) {\n(string) \"\"\n}\n"},
+		{scsNoLen, fCSSdump, "", [1]string{"a"}, "([1]string) {\n(string) \"a\"\n}\n"},
+		{scsNoLen, fCSSdump, "", map[int]int{1: 2}, "(map[int]int) {\n(int) 1: (int) 2\n}\n"},
+		{scsNoLen, fCSSdump, "", "ab", "(string) \"ab\"\n"},
+		{scsNoLen, fCSSdump, "", lenCapCh, fmt.Sprintf("(chan int) (cap=3) %p\n", lenCapCh)},
+		{scsNoCap, fCSSdump, "", lenCapCh, fmt.Sprintf("(chan int) (len=1) %p\n", lenCapCh)},
+		{scsNoLenCap, fCSSdump, "", make([]string, 1, 10), "([]string) {\n(string) \"\"\n}\n"},
+		{scsNoLenCap, fCSSdump, "", map[int]int{1: 2}, "(map[int]int) {\n(int) 1: (int) 2\n}\n"},
+		{scsMaxStr, fCSSdump, "", "hello world", "(string) (len=11) \"hello\"...(truncated, 11 bytes)\n"},
+		{scsMaxStr, fCSSdump, "", "hello", "(string) (len=5) \"hello\"\n"},
+		{scsMaxStr, fCSSdump, "", "héllö wörld", "(string) (len=14) \"héllö\"...(truncated, 14 bytes)\n"},
+		{scsMaxStr, fCSFprint, "", "hello world", "hello...(truncated, 11 bytes)"},
+		{scsMaxStr, fCSFprint, "", "日本語のテキスト", "日本語のテ...(truncated, 24 bytes)"},
+		{scsMaxSlice, fCSSdump, "", []int{1, 2, 3, 4, 5}, "([]int) (len=5 cap=5) {\n" +
+			" (int) 1,\n (int) 2,\n ... (3 more elements)\n}\n"},
+		{scsMaxSlice, fCSSdump, "", [2]int{1, 2}, "([2]int) (len=2) {\n" +
+			" (int) 1,\n (int) 2\n}\n"},
+		{scsMaxSlice, fCSSdump, "", []byte{1, 2, 3}, "([]uint8) (len=3 cap=3) {\n" +
+			" 00000000  01 02                                             |..|\n" +
+			" ... (1 more elements)\n}\n"},
+		{scsMaxSlice, fCSFprint, "", []int{1, 2, 3, 4, 5}, "[1 2 ... (3 more elements)]"},
+		{scsMaxSlice, fCSFprint, "", []int{1, 2}, "[1 2]"},
+		{scsMaxMap, fCSSdump, "", map[int]int{3: 30, 1: 10, 2: 20, 4: 40},
+			"(map[int]int) (len=4) {\n (int) 1: (int) 10,\n (int) 2: (int) 20,\n" +
+				" ... (2 more entries)\n}\n"},
+		{scsMaxMap, fCSSdump, "", map[int]int{1: 10}, "(map[int]int) (len=1) {\n" +
+			" (int) 1: (int) 10\n}\n"},
+		{scsMaxMap, fCSFprint, "", map[int]int{3: 30, 1: 10, 2: 20},
+			"map[1:10 2:20 ... (1 more entries)]"},
+		{scsMaxFields, fCSSdump, "", tagRename{UserID: 1}, "(spew_test.tagRename) {\n" +
+			" user_id: (int) 1,\n ... (3 more fields)\n}\n"},
+		{scsMaxFields, fCSSdump, "", struct{ A int }{1}, "(struct { A int }) {\n" +
+			" A: (int) 1\n}\n"},
+		{scsMaxFields, fCSSprintf, "%+v", tagRename{UserID: 1},
+			"{user_id:1 ... (3 more fields)}"},
+		{scsMaxFieldsSorted, fCSSprint, "", struct{ C, B, A int }{3, 2, 1},
+			"{1 2 ... (1 more fields)}"},
+		{scsHeaderAddrs, fCSSdump, "", struct{ M, N map[string]int }{headerMap, headerMap},
+			fmt.Sprintf("(struct { M map[string]int; N map[string]int }) {\n"+
+				" M: (map[string]int)(%[1]p) (len=1) {\n  (string) (len=1) \"a\": (int) 1\n },\n"+
+				" N: (map[string]int)(%[1]p) (len=1) {\n  (string) (len=1) \"a\": (int) 1\n }\n}\n",
+				headerMap)},
+		{scsHeaderAddrs, fCSSdump, "", headerSlice, fmt.Sprintf("([]int)(%p) (len=1 cap=1) {\n"+
+			" (int) 1\n}\n", headerSlice)},
+		{scsHeaderAddrs, fCSSdump, "", []int(nil), "([]int) <nil>\n"},
+		{scsDefault, fCSSdump, "", struct{ Err error }{findError()},
+			"(struct { Err error }) {\n Err: (error = *spew_test.nilError)(<nil>)\n}\n"},
+		{scsDefault, fCSSdump, "", []error{findError(), nil}, "([]error) (len=2 cap=2) {\n" +
+			" (error = *spew_test.nilError)(<nil>),\n (error) <nil>\n}\n"},
+		{scsDefault, fCSSdump, "", map[string]interface{}{"m": map[int]int(nil)},
+			"(map[string]interface {}) (len=1) {\n" +
+				" (string) (len=1) \"m\": (interface {} = map[int]int) <nil>\n}\n"},
+		{scsCounts, fCSSdump, "", map[string]int{"a": 1, "b": 2},
+			"(map[string]int) (count=2) {\n (string) \"a\": (int) 1,\n" +
+				" ... (1 more entries)\n}\n"},
+		{scsCounts, fCSSdump, "", []int{1, 2, 3}, "([]int) (count=3) {\n" +
+			" (int) 1,\n ... (2 more elements)\n}\n"},
+		{scsCounts, fCSSdump, "", [0]int{}, "([0]int) (count=0) {}\n"},
+		{scsCounts, fCSSdump, "", []int(nil), "([]int) <nil>\n"},
+		{scsBytesStr, fCSSdump, "", []byte("hello"), "([]uint8) (len=5 cap=5) \"hello\"\n"},
+		{scsBytesStr, fCSSdump, "", [3]byte{'a', 'b', 'c'}, "([3]uint8) (len=3) \"abc\"\n"},
+		{scsBytesStr, fCSSdump, "", []byte("héllo"), "([]uint8) (len=6 cap=6) \"héllo\"\n"},
+		{scsBytesStr, fCSSdump, "", []byte("hi\n"), "([]uint8) (len=3 cap=3) {\n" +
+			" 00000000  68 69 0a                                          |hi.|\n}\n"},
+		{scsBytesStr, fCSSdump, "", []byte{0xff, 'a'}, "([]uint8) (len=2 cap=2) {\n" +
+			" 00000000  ff 61                                             |.a|\n}\n"},
+		{scsIndentWidth, fCSSdump, "", [][]int{{1}}, "([][]int) (len=1 cap=1) {\n" +
+			"    ([]int) (len=1 cap=1) {\n        (int) 1\n    }\n}\n"},
+		{scsIndentBoth, fCSSdump, "", []int{1}, "([]int) (len=1 cap=1) {\n\t(int) 1\n}\n"},
+		{scsNoQuote, fCSSdump, "", "hello world", "(string) (len=11) hello world\n"},
+		{scsNoQuote, fCSSdump, "", struct{ S string }{"a\nb"}, "(struct { S string }) {\n" +
+			" S: (string) (len=3) a\n  b\n}\n"},
+		{scsNoQuote, fCSSdump, "", map[string]int{"k": 1}, "(map[string]int) (len=1) {\n" +
+			" (string) (len=1) k: (int) 1\n}\n"},
+		{scsExported, fCSSdump, "", tagRename{UserID: 1, name: "n"}, "(spew_test.tagRename) {\n" +
+			" user_id: (int) 1,\n -: (int) 0,\n Plain: (int) 0\n}\n"},
+		{scsExported, fCSSdump, "", stringerWrap{"a", 1}, "(spew_test.stringerWrap) stringer a\n"},
+		{scsExported, fCSSdump, "", ew, "(spew_test.embedwrap) {}\n"},
+		{scsExported, fCSSprintf, "%+v", struct {
+			a int
+			B int
+		}{1, 2}, "{B:2}"},
+		{scsDefault, fCSSdump, "", stringerWrap{"a", 1}, "(spew_test.stringerWrap) stringer a\n"},
+		{scsDirect, fCSSdump, "", stringerWrap{"a", 1}, "(spew_test.stringerWrap) {\n" +
+			" stringer: (spew_test.stringer) (len=1) " + wrapField + ",\n N: (int) 1\n}\n"},
+		{scsDirect, fCSSdump, "", &stringerWrap{"a", 1}, "(*spew_test.stringerWrap)({\n" +
+			" stringer: (spew_test.stringer) (len=1) " + wrapField + ",\n N: (int) 1\n})\n"},
+		{scsDirect, fCSSdump, "", stringerOverride{"a"}, "(spew_test.stringerOverride) override\n"},
+		{scsDirect, fCSSdump, "", &stringerOverride{"a"}, "(*spew_test.stringerOverride)(override)\n"},
+		{scsDirect, fCSSprint, "", stringerOverride{"a"}, "override"},
+		{scsDirect, fCSSprint, "", stringer("a"), "stringer a"},
+		{scsMaxArrayBytes, fCSSdump, "", hash, "([32]uint8) (len=32) " +
+			"<32 bytes: 00010203...1c1d1e1f>\n"},
+		{scsMaxArrayBytes, fCSSdump, "", hash[:5], "([]uint8) (len=5 cap=32) " +
+			"<5 bytes: 0001020304>\n"},
+		{scsMaxArrayBytes, fCSSdump, "", []byte{0xab}, "([]uint8) (len=1 cap=1) {\n" +
+			" 00000000  ab                                                |.|\n}\n"},
+		{scsMaxArrayBytes, fCSSdump, "", []int{1, 2, 3, 4}, "([]int) (len=4 cap=4) {\n" +
+			" (int) 1,\n (int) 2,\n (int) 3,\n (int) 4\n}\n"},
+		{scsRunesStr, fCSSdump, "", []rune("héllo"), "([]int32) (len=5 cap=5) \"héllo\"\n"},
+		{scsRunesStr, fCSSdump, "", [2]rune{'a', '\n'}, "([2]int32) (len=2) \"a\\n\"\n"},
+		{scsRunesStr, fCSSdump, "", []rune{'a', 0x00a0}, "([]int32) (len=2 cap=2) \"a\\u00a0\"\n"},
+		{scsRunesStr, fCSSdump, "", []rune{}, "([]int32) {}\n"},
+		{scsRawStr, fCSSdump, "", "日本\u00a0\"\\", "(string) (len=10) \"日本\u00a0\\\"\\\\\"\n"},
+		{scsRawStr, fCSSdump, "", "a\tb\u0085\xff", "(string) (len=6) \"a\\x09b\\xc2\\x85\\xff\"\n"},
+		{scsRawStr, fCSSdump, "", []rune("é\n"), "([]int32) (len=2 cap=2) \"é\\x0a\"\n"},
+		{scsRunesStr, fCSSdump, "", []rune{'a', -1}, "([]int32) (len=2 cap=2) {\n" +
+			" (int32) 97,\n (int32) -1\n}\n"},
+		{scsDedup, fCSSdump, "", ew, "(spew_test.embedwrap) {\n" +
+			" embed: (*spew_test.embed)({\n  a: (string) (len=1) \"x\"\n }),\n" +
+			" e: (*spew_test.embed)(<already dumped>)\n}\n"},
+		{scsDedup, fCSSdump, "", tptr, "(*spew_test.ptrTester)({\n" +
+			" s: (*struct {})({})\n})\n"},
+		{scsMaxOutput, fCSSdump, "", []int{1, 2, 3, 4, 5}, "([]int) (len=5 cap=5\n" +
+			"... (output truncated at 20 bytes)\n"},
+		{scsMaxOutput, fCSSdump, "", 5, "(int) 5\n"},
+		{scsMaxOutput, fCSSdump, "", "0123456789012345678", "(string) (len=19) \"0\n" +
+			"... (output truncated at 20 bytes)\n"},
+		{scsEmbedded, fCSSdump, "", ew, "(spew_test.embedwrap) {\n" +
+			" <embedded> embed: (*spew_test.embed)({\n  a: (string) (len=1) \"x\"\n }),\n" +
+			" e: (*spew_test.embed)({\n  a: (string) (len=1) \"x\"\n })\n}\n"},
+		{scsEmbedded, fCSSdump, "", struct {
+			embed
+			N int
+		}{embed{"y"}, 1}, "(struct { spew_test.embed; N int }) {\n" +
+			" <embedded> embed: (spew_test.embed) {\n  a: (string) (len=1) \"y\"\n },\n" +
+			" N: (int) 1\n}\n"},
+		{scsPolar, fCSSdump, "", complex(3, 4), "(complex128) (5∠0.9272952180016122)\n"},
+		{scsPolar, fCSSprint, "", complex64(complex(0, -2)), "(2∠-1.5707964)"},
+		{scsPolarPrec, fCSSdump, "", complex(3, 4), "(complex128) (5∠0.927)\n"},
+		{scsRectPrec, fCSSdump, "", complex(1.0/3, -2), "(complex128) (0.33-2i)\n"},
+		{scsFloatSpecial, fCSSdump, "", math.NaN(), "(float64) math.NaN()\n"},
+		{scsFloatSpecial, fCSSdump, "", float32(math.Inf(1)), "(float32) math.Inf(1)\n"},
+		{scsFloatSpecial, fCSSdump, "", math.Inf(-1), "(float64) math.Inf(-1)\n"},
+		{scsFloatSpecial, fCSSdump, "", 1.5, "(float64) 1.5\n"},
+		{scsFloatSpecial, fCSSdump, "", complex(math.Inf(1), 2),
+			"(complex128) complex(math.Inf(1), 2)\n"},
+		{scsFloatSpecial, fCSSprint, "", []float64{math.NaN(), 1},
+			"[math.NaN() 1]"},
+		{scsKind, fCSSdump, "", tagSkip{1, "pw", 2},
+			"(spew_test.tagSkip kind=struct) {\n" +
+				" A: (int kind=int) 1,\n B: (int kind=int) 2\n}\n"},
+		{scsKind, fCSSdump, "", &ew.e, "(**spew_test.embed kind=struct)({\n" +
+			" a: (string kind=string) (len=1) \"x\"\n})\n"},
+		{scsAliases, fCSSdump, "", ew, "(spew_test.embedwrap) {\n" +
+			" embed: (*spew_test.embed)(0x1)({\n  a: (string) (len=1) \"x\"\n }),\n" +
+			" e: (*spew_test.embed)(0x1)({\n  a: (string) (len=1) \"x\"\n })\n}\n"},
+		{scsAliases, fCSSdump, "", []interface{}{&aliasPtr, aliasPtr, (*int)(nil)},
+			"([]interface {}) (len=3 cap=3) {\n" +
+				" (**int)(0x1->0x2)(5),\n (*int)(0x2)(5),\n" +
+				" (interface {} = *int)(<nil>)\n}\n"},
+		{scsColors, fCSSdump, "", struct {
+			A int
+			B string
+			C *int
+		}{1, "x", nil}, "(<t>struct { A int; B string; C *int }\x1b[0m) {\n" +
+			" <f>A\x1b[0m: (<t>int\x1b[0m) <n>1\x1b[0m,\n" +
+			" <f>B\x1b[0m: (<t>string\x1b[0m) (len=1) <s>\"x\"\x1b[0m,\n" +
+			" <f>C\x1b[0m: (<t>*int\x1b[0m)(<0><nil>\x1b[0m)\n}\n"},
+		{scsColors, fCSSdump, "", nil, "(<t>interface {}\x1b[0m) <0><nil>\x1b[0m\n"},
+		{scsColors, fCSSdump, "", uintptr(0x10), "(<t>uintptr\x1b[0m) <p>0x10\x1b[0m\n"},
+		{scsDefColors, fCSSdump, "", 5, "(\x1b[32mint\x1b[0m) \x1b[34m5\x1b[0m\n"},
+		{scsCompact, fCSSdump, "", struct {
+			A int
+			B string
+		}{1, "x"}, "(struct { A int; B string }){A:(int)1, B:(string)(len=1)\"x\"}\n"},
+		{scsCompact, fCSSdump, "", map[string]int{"a": 1}, "(map[string]int)(len=1){" +
+			"(string)(len=1)\"a\":(int)1}\n"},
+		{scsCompact, fCSSdump, "", []int{1, 2, 3, 4}, "([]int)(len=4 cap=4){" +
+			"(int)1, (int)2, (int)3, ... (1 more elements)}\n"},
+		{scsCompact, fCSSdump, "", []byte{1, 0xab, 3, 4}, "([]uint8)(len=4 cap=4){" +
+			"01 ab 03 ... (1 more elements)}\n"},
+		{scsCompact, fCSSdump, "", ew, "(spew_test.embedwrap){" +
+			"embed:(*spew_test.embed)({a:(string)(len=1)\"x\"}), " +
+			"e:(*spew_test.embed)({a:(string)(len=1)\"x\"})}\n"},
+		{scsCompact, fCSSdump, "", xr, "(*spew_test.xref1)({" +
+			"ps2:(*spew_test.xref2)({ps1:(*spew_test.xref1)(<already shown>)})})\n"},
+		{scsDefault, fCSSdump, "", tagSkip{1, "pw", 2}, "(spew_test.tagSkip) {\n" +
+			" A: (int) 1,\n B: (int) 2\n}\n"},
+		{scsDefault, fCSSdump, "", tagSkipLast{A: 1}, "(spew_test.tagSkipLast) {\n" +
+			" A: (int) 1\n}\n"},
+		{scsDefault, fCSFprintf, "%+v", tagSkip{1, "pw", 2}, "{A:1 B:2}"},
+		{scsDefault, fCSFprint, "", tagSkipLast{A: 1}, "{1}"},
+		{scsDefault, fCSSdump, "", tagRename{1, "x", 2, 3}, "(spew_test.tagRename) {\n" +
+			" user_id: (int) 1,\n displayName: (string) (len=1) \"x\",\n" +
+			" -: (int) 2,\n Plain: (int) 3\n}\n"},
+		{scsDefault, fCSFprintf, "%+v", tagRename{1, "x", 2, 3},
+			"{user_id:1 displayName:x -:2 Plain:3}"},
+		{scsRedact, fCSSdump, "", rr, "(spew_test.redactReq) {\n" +
+			" User: (string) (len=3) \"bob\",\n" +
+			" Password: (string) ****,\n" +
+			" Tokens: (map[string]string) (len=1) {\n" +
+			"  (string) (len=3) \"api\": (string) <.Tokens[\"api\"]>\n },\n" +
+			" Keys: ([]int) (len=2) {\n  (int) 1,\n  (int) <.Keys[1]>\n }\n}\n"},
+		{scsRedact, fCSSdump, "", &rr, "(*spew_test.redactReq)(" + fmt.Sprintf("%p", &rr) + ")({\n" +
+			" User: (string) (len=3) \"bob\",\n" +
+			" Password: (string) ****,\n" +
+			" Tokens: (map[string]string) (len=1) {\n" +
+			"  (string) (len=3) \"api\": (string) <.Tokens[\"api\"]>\n },\n" +
+			" Keys: ([]int) (len=2) {\n  (int) 1,\n  (int) <.Keys[1]>\n }\n})\n"},
+		{scsRedact, fCSFprintf, "%+v", rr, "{User:bob Password:**** " +
+			"Tokens:map[api:<.Tokens[\"api\"]>] Keys:[1 <.Keys[1]>]}"},
+		{scsOmitFunc, fCSSdump, "", rr, "(spew_test.redactReq) {\n" +
+			" User: (string) (len=3) \"bob\",\n" +
+			" Tokens: (map[string]string) (len=1) {\n" +
+			"  (string) (len=3) \"api\": (string) (len=6) \"secret\"\n },\n" +
+			" Keys: ([]int) (len=2) {\n  (int) 1,\n  (int) 2\n }\n}\n"},
+		{scsOmitFunc, fCSSdump, "", []interface{}{(*int)(nil), 3, rr},
+			"([]interface {}) (len=3) {\n" +
+				" (int) 3,\n" +
+				" (spew_test.redactReq) {\n" +
+				"  User: (string) (len=3) \"bob\",\n" +
+				"  Tokens: (map[string]string) (len=1) {\n  },\n" +
+				"  Keys: ([]int) (len=2) {\n   (int) 2\n  }\n }\n}\n"},
+		{scsOmitFunc, fCSSdump, "", struct{ P *int }{}, "(struct { P *int }) {}\n"},
+		{scsNoMethods, fCSSdump, "", tm, "(time.Time) 2009-11-10T23:00:00.000000005Z\n"},
+		{scsNoMethods, fCSSdump, "", timeHolder{tm}, "(spew_test.timeHolder) {\n" +
+			" T: (time.Time) 2009-11-10T23:00:00.000000005Z\n}\n"},
+		{scsNoMethods, fCSFprint, "", tm, "2009-11-10T23:00:00.000000005Z"},
+		{scsDefault, fCSSdump, "", tm, "(time.Time) 2009-11-10 23:00:00.000000005 +0000 UTC\n"},
+		{scsNoTimeFmt, fCSFprint, "", timeHolder{}, "{{0 0 <nil>}}"},
+		{scsNoMethods, fCSSdump, "", bh, "(spew_test.bigHolder) {\n" +
+			" I: (big.Int) 123456789012345678901234567890,\n" +
+			" R: (*big.Rat)(" + fmt.Sprintf("%p", bh.R) + ")(3/4),\n" +
+			" F: (*big.Float)(" + fmt.Sprintf("%p", bh.F) + ")(1.5)\n}\n"},
+		{scsNoMethods, fCSFprint, "", bh, "{123456789012345678901234567890 <*>3/4 <*>1.5}"},
+		{scsDefault, fCSFprint, "", bi, "<*>123456789012345678901234567890"},
+		{scsRuneChar, fCSSdump, "", []rune("A\u00e9\x01"), "([]int32) (len=3 cap=3) {\n" +
+			" (int32) 'A' (65),\n (int32) 'é' (233),\n (int32) '\\u0001' (1)\n}\n"},
+		{scsRuneChar, fCSSdump, "", rune(0x10ffff), "(int32) '\\U0010ffff' (1114111)\n"},
+		{scsRuneChar, fCSSdump, "", int32(-1), "(int32) -1\n"},
+		{scsRuneChar, fCSSdump, "", int64(65), "(int64) 65\n"},
+		{scsRuneChar, fCSFprint, "", []rune("ab"), "['a' 'b']"},
+		{scsHex, fCSSdump, "", []int{127, -127}, "([]int) (len=2 cap=2) {\n" +
+			" (int) 0x7f,\n (int) -0x7f\n}\n"},
+		{scsHex, fCSSdump, "", int64(math.MinInt64), "(int64) -0x8000000000000000\n"},
+		{scsHex, fCSSdump, "", uint8(255), "(uint8) 0xff\n"},
+		{scsHex, fCSSdump, "", uintptr(255), "(uintptr) 0xff\n"},
+		{scsHex, fCSFprint, "", map[string]uint{"a": 10}, "map[a:0xa]"},
+		{scsBinary, fCSSdump, "", uint16(5), "(uint16) 0b101\n"},
+		{scsOctal, fCSFprint, "", 8, "0o10"},
+		{scsBase36, fCSFprint, "", 35, "z"},
+		{scsFloat3f, fCSSdump, "", 1.0 / 3, "(float64) 0.333\n"},
+		{scsFloat3f, fCSSdump, "", float32(2.5), "(float32) 2.500\n"},
+		{scsFloat3f, fCSSdump, "", complex(1.0/3, -2), "(complex128) (0.333-2.000i)\n"},
+		{scsFloat3f, fCSFprint, "", []float64{1, 0.12345}, "[1.000 0.123]"},
+		{scsFloatE, fCSFprint, "", 1234.5, "1.2345e+03"},
+		{scsFloat0f, fCSSdump, "", 2.7, "(float64) 3\n"},
+		{scsFloat0f, fCSFprint, "", complex(1.25, -0.5), "(1-0i)"},
+		{scsFloat0, fCSSdump, "", 2.7, "(float64) 2.7\n"},
+		{scsSortFields, fCSFprintf, "%+v", sortFields{1, 2, nil, 3},
+			"{Zeta:1 aardvark:3 alpha:2 embed:<nil>}"},
+		{scsSortFields, fCSSdump, "", sf, "(spew_test.sortFields) {\n" +
+			" Zeta: (int) 1,\n aardvark: (int) 3,\n alpha: (int) 2,\n" +
+			" embed: (*spew_test.embed)(" + fmt.Sprintf("%p", sf.embed) + ")({\n" +
+			"  a: (string) (len=1) \"e\"\n })\n}\n"},
+		{scsOmitZero, fCSSdump, "", oz, "(spew_test.omitZero) {\n" +
+			" F: ([2]bool) (len=2) {\n  (bool) false,\n  (bool) true\n },\n" +
+			" H: (string) (len=3) \"set\"\n}\n"},
+		{scsOmitZero, fCSSdump, "", omitZero{E: struct{ X, Y float64 }{0, 1}},
+			"(spew_test.omitZero) {\n E: (struct { X float64; Y float64 }) {\n" +
+				"  Y: (float64) 1\n }\n}\n"},
+		{scsOmitZero, fCSSdump, "", map[string]int{"a": 0, "b": 1, "c": 0},
+			"(map[string]int) (len=3) {\n (string) (len=1) \"b\": (int) 1\n}\n"},
+		{scsOmitZero, fCSFprint, "", map[string]int{"a": 0, "b": 1}, "map[b:1]"},
+		{scsOmitZero, fCSFprintf, "%+v", oz, "{F:[false true] H:set}"},
+		{scsMaxChain, fCSSdump, "", cp3, fmt.Sprintf("(***int)(%p->%p->...)(5)\n",
+			cp3, cp2)},
+		{scsMaxChain, fCSSdump, "", cp2, fmt.Sprintf("(**int)(%p->%p)(5)\n", cp2, cp1)},
+		{scsMaxChain, fCSFprintf, "%+v", cp3, fmt.Sprintf("<***>(%p->%p->...)5", cp3, cp2)},
+		{scsCompactDepth, fCSSdump, "", [][]int{{1}}, "([][]int)(len=1 cap=1){" +
+			"([]int)(len=1 cap=1){<max depth reached>}}\n"},
 	}
 }
 
@@ -246,6 +818,9 @@ func TestSpew(t *testing.T) {
 			str := test.cs.Sdump(test.in)
 			buf.WriteString(str)
 
+		case fCSDumpb:
+			buf.Write(test.cs.Dumpb(test.in))
+
 		case fCSSprint:
 			str := test.cs.Sprint(test.in)
 			buf.WriteString(str)
@@ -295,6 +870,9 @@ func TestSpew(t *testing.T) {
 			str := spew.Sdump(test.in)
 			buf.WriteString(str)
 
+		case fDumpb:
+			buf.Write(spew.Dumpb(test.in))
+
 		case fSprint:
 			str := spew.Sprint(test.in)
 			buf.WriteString(str)
@@ -318,3 +896,22 @@ func TestSpew(t *testing.T) {
 		}
 	}
 }
+
+// TestConfigStateReset ensures Reset restores the default settings in place.
+func TestConfigStateReset(t *testing.T) {
+	cs := spew.NewConfig(spew.WithIndent("\t"), spew.WithMaxDepth(1),
+		spew.WithSortKeys())
+	cs.Redact = func(string, reflect.Value) (string, bool) { return "", true }
+	cs.Reset()
+
+	want := spew.NewDefaultConfig().Sdump(map[string][]int{"a": {1}})
+	if got := cs.Sdump(map[string][]int{"a": {1}}); got != want {
+		t.Errorf("Reset\n got: %s want: %s", got, want)
+	}
+
+	var zero spew.ConfigState
+	zero.Reset()
+	if got := zero.Sdump(5); got != "(int) 5\n" {
+		t.Errorf("Reset zero value\n got: %s want: %s", got, "(int) 5\n")
+	}
+}
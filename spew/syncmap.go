@@ -0,0 +1,92 @@
+// Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// NOTE: Due to the following build constraints, this file will only be compiled
+// with Go 1.9 and later since that is when sync.Map was introduced.
+// +build go1.9
+
+package spew
+
+import (
+	"reflect"
+	"sync"
+)
+
+// syncMapType is a reflect.Type representing a sync.Map.  It is used to detect
+// sync.Maps so their contents can be displayed instead of their internals.
+var syncMapType = reflect.TypeOf((*sync.Map)(nil)).Elem()
+
+// interfaceType is a reflect.Type representing an interface{}.
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// syncMapContents returns a regular map which holds a snapshot of the entries
+// stored in the passed value, which must be a sync.Map.  The entries are
+// collected via the Range method since, unlike the internals, it is safe for
+// concurrent use.  The keys of the returned map have the same concrete type as
+// the stored keys when they all share one so they can be sorted, and
+// interface{} otherwise.  As with handleMethods, unsafe is used, when it's
+// available, to access sync.Maps which are stored in unexported struct fields.
+// The boolean return is false when the value can't be accessed.
+func syncMapContents(v reflect.Value) (reflect.Value, bool) {
+	if !v.CanInterface() {
+		if UnsafeDisabled {
+			return reflect.Value{}, false
+		}
+
+		v = unsafeReflectValue(v)
+		if !v.CanInterface() {
+			return reflect.Value{}, false
+		}
+	}
+
+	// Range requires a pointer receiver, so make an addressable copy when
+	// the value itself isn't addressable.
+	if !v.CanAddr() {
+		pv := reflect.New(syncMapType)
+		pv.Elem().Set(v)
+		v = pv.Elem()
+	}
+	m := v.Addr().Interface().(*sync.Map)
+
+	var keys, values []interface{}
+	m.Range(func(key, value interface{}) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		return true
+	})
+
+	keyType := interfaceType
+	for i, key := range keys {
+		kt := reflect.TypeOf(key)
+		if i == 0 {
+			keyType = kt
+		}
+		if kt == nil || kt != keyType {
+			keyType = interfaceType
+			break
+		}
+	}
+
+	contents := reflect.MakeMap(reflect.MapOf(keyType, interfaceType))
+	for i := range keys {
+		// Index into the slices so nil keys and values remain valid
+		// interface{} values instead of becoming invalid ones.
+		key := reflect.ValueOf(&keys[i]).Elem()
+		if keyType != interfaceType {
+			key = key.Elem()
+		}
+		contents.SetMapIndex(key, reflect.ValueOf(&values[i]).Elem())
+	}
+	return contents, true
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// NOTE: Due to the following build constraints, this file will only be compiled
+// with Go 1.9 and later since that is when sync.Map was introduced.
+// +build go1.9
+
+package spew_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestSyncMap ensures the entries of sync.Map values are dumped like a regular
+// map unless DisableSyncMapExpansion is set.
+func TestSyncMap(t *testing.T) {
+	cs := &spew.ConfigState{Indent: " ", SortKeys: true}
+	var m sync.Map
+	m.Store("b", 2)
+	m.Store("a", 1)
+	entries := "(len=2) {\n" +
+		" (string) (len=1) \"a\": (int) 1,\n" +
+		" (string) (len=1) \"b\": (int) 2\n" +
+		"}"
+
+	got := cs.Sdump(&m)
+	want := fmt.Sprintf("(*sync.Map)(%p)(%s)\n", &m, entries)
+	if got != want {
+		t.Errorf("sync.Map pointer\n got: %s want: %s", got, want)
+	}
+
+	s := struct{ M *sync.Map }{&m}
+	got = cs.Sdump(s)
+	want = fmt.Sprintf("(struct { M *sync.Map }) {\n M: (*sync.Map)(%p)(%s)\n}\n",
+		&m, strings.Replace(entries, "\n", "\n ", -1))
+	if got != want {
+		t.Errorf("sync.Map field\n got: %s want: %s", got, want)
+	}
+
+	var empty sync.Map
+	got = cs.Sdump(&empty)
+	want = fmt.Sprintf("(*sync.Map)(%p)({})\n", &empty)
+	if got != want {
+		t.Errorf("empty sync.Map\n got: %s want: %s", got, want)
+	}
+
+	cs.DisableSyncMapExpansion = true
+	got = cs.Sdump(&m)
+	if strings.Contains(got, "len=2") || strings.Contains(got, "\"a\"") {
+		t.Errorf("sync.Map with DisableSyncMapExpansion\n got: %s", got)
+	}
+}
@@ -0,0 +1,274 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// TokenKind identifies the kind of a Token produced by Tokens.
+type TokenKind int
+
+// These constants define the kinds of tokens produced by Tokens.
+const (
+	// TokenScalar is a value without any nested values, such as a number
+	// or string, as well as any value displayed via its error or Stringer
+	// interface.  Its Text is the value as displayed by the Formatter.
+	TokenScalar TokenKind = iota
+
+	// TokenNil is a nil pointer, interface, slice, map, channel, or
+	// function.
+	TokenNil
+
+	// TokenPointer is a non-nil pointer.  It is followed by the tokens for
+	// the value it points to.
+	TokenPointer
+
+	// TokenCycle is a pointer, slice, or map which refers to a value that is
+	// already being visited.  It is not followed by any tokens for the value.
+	TokenCycle
+
+	// TokenBeginStruct starts a struct.  Each of its fields is a
+	// TokenFieldName followed by the tokens for the field value, and it is
+	// finished by a TokenEnd.
+	TokenBeginStruct
+
+	// TokenFieldName is the name of a struct field.
+	TokenFieldName
+
+	// TokenBeginSlice starts an array or slice.  It is followed by the tokens
+	// for each element and finished by a TokenEnd.
+	TokenBeginSlice
+
+	// TokenBeginMap starts a map.  Each of its entries is a TokenMapKey
+	// followed by the tokens for the key and then the tokens for the value,
+	// and it is finished by a TokenEnd.
+	TokenBeginMap
+
+	// TokenMapKey precedes the tokens for the key of a map entry.
+	TokenMapKey
+
+	// TokenMaxDepth stands in for the contents of a struct, array, slice, or
+	// map which is nested deeper than the MaxDepth option allows.
+	TokenMaxDepth
+
+	// TokenEnd finishes a struct, array, slice, or map.
+	TokenEnd
+)
+
+// tokenKindStrings maps TokenKind values to their names.
+var tokenKindStrings = map[TokenKind]string{
+	TokenScalar:      "Scalar",
+	TokenNil:         "Nil",
+	TokenPointer:     "Pointer",
+	TokenCycle:       "Cycle",
+	TokenBeginStruct: "BeginStruct",
+	TokenFieldName:   "FieldName",
+	TokenBeginSlice:  "BeginSlice",
+	TokenBeginMap:    "BeginMap",
+	TokenMapKey:      "MapKey",
+	TokenMaxDepth:    "MaxDepth",
+	TokenEnd:         "End",
+}
+
+// String returns the TokenKind in human-readable form.
+func (k TokenKind) String() string {
+	if s, ok := tokenKindStrings[k]; ok {
+		return s
+	}
+	return "Unknown"
+}
+
+// Token is a single element of the stream produced by Tokens.
+type Token struct {
+	// Kind is the kind of the token.
+	Kind TokenKind
+
+	// Type is the type of the value the token is for.  It is nil for
+	// TokenFieldName, TokenMapKey, TokenMaxDepth, and TokenEnd tokens as well
+	// as for the TokenNil token for a nil argument.
+	Type reflect.Type
+
+	// Value is the value the token is for.  It is only set for TokenScalar
+	// tokens.
+	Value reflect.Value
+
+	// Text is the value as displayed by the Formatter for TokenScalar tokens
+	// and the name of the field for TokenFieldName tokens.
+	Text string
+
+	// Len is the number of elements or entries for TokenBeginSlice and
+	// TokenBeginMap tokens.
+	Len int
+
+	// Addr is the address for TokenPointer and TokenCycle tokens.
+	Addr uintptr
+}
+
+// tokenState contains information about the state of a tokenize operation.
+type tokenState struct {
+	emit     func(Token)
+	depth    int
+	pointers map[uintptr]bool
+	cs       *ConfigState
+}
+
+// begin emits the passed token which starts a struct, array, slice, or map.
+// It returns whether or not the contents should be emitted, which is false
+// once the maximum depth is exceeded, in which case a TokenMaxDepth and
+// TokenEnd are emitted instead.
+func (ts *tokenState) begin(tok Token) bool {
+	ts.emit(tok)
+	ts.depth++
+	if ts.cs.depthExceeded(ts.depth) {
+		ts.emit(Token{Kind: TokenMaxDepth})
+		ts.end()
+		return false
+	}
+	return true
+}
+
+// end emits a TokenEnd which finishes a struct, array, slice, or map.
+func (ts *tokenState) end() {
+	ts.depth--
+	ts.emit(Token{Kind: TokenEnd})
+}
+
+// tokenize emits the tokens for the passed value.  Interfaces are transparent
+// while pointers are followed to the values they reference, however circular
+// references are not followed again.
+func (ts *tokenState) tokenize(v reflect.Value) {
+	// Unwrap interfaces to their concrete values.
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		ts.emit(Token{Kind: TokenNil})
+		return
+	}
+
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map,
+		reflect.Chan, reflect.Func, reflect.UnsafePointer:
+
+		if v.IsNil() {
+			ts.emit(Token{Kind: TokenNil, Type: t})
+			return
+		}
+	}
+
+	// Detect circular pointers, slices, and maps.
+	var addr uintptr
+	if v.Kind() == reflect.Ptr {
+		addr = v.Pointer()
+	} else {
+		addr = refAddr(v)
+	}
+	if addr != 0 {
+		if ts.pointers[addr] {
+			ts.emit(Token{Kind: TokenCycle, Type: t, Addr: addr})
+			return
+		}
+		ts.pointers[addr] = true
+		defer delete(ts.pointers, addr)
+	}
+
+	if v.Kind() == reflect.Ptr {
+		ts.emit(Token{Kind: TokenPointer, Type: t, Addr: addr})
+		ts.tokenize(v.Elem())
+		return
+	}
+
+	// Values which are displayed via their error or Stringer interfaces or
+	// in a readable form are scalars.
+	var buf bytes.Buffer
+	if !ts.cs.DisableMethods && handleMethods(ts.cs, &buf, v) ||
+		handleKnownTypes(ts.cs, &buf, v) {
+
+		ts.emit(Token{Kind: TokenScalar, Type: t, Value: v,
+			Text: buf.String()})
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		numEntries := v.Len()
+		if !ts.begin(Token{Kind: TokenBeginSlice, Type: t, Len: numEntries}) {
+			return
+		}
+		for i := 0; i < numEntries; i++ {
+			ts.tokenize(v.Index(i))
+		}
+		ts.end()
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		if ts.cs.OmitZero {
+			keys = nonZeroKeys(v, keys)
+		}
+		if ts.cs.SortKeys {
+			sortValues(keys, ts.cs)
+		}
+		if !ts.begin(Token{Kind: TokenBeginMap, Type: t, Len: len(keys)}) {
+			return
+		}
+		for _, key := range keys {
+			ts.emit(Token{Kind: TokenMapKey})
+			ts.tokenize(key)
+			ts.tokenize(v.MapIndex(key))
+		}
+		ts.end()
+
+	case reflect.Struct:
+		fields := visibleFields(ts.cs, t)
+		if ts.cs.OmitZero {
+			fields = nonZeroFields(v, fields)
+		}
+		if !ts.begin(Token{Kind: TokenBeginStruct, Type: t}) {
+			return
+		}
+		for _, fi := range fields {
+			ts.emit(Token{Kind: TokenFieldName, Text: fieldName(t.Field(fi))})
+			ts.tokenize(v.Field(fi))
+		}
+		ts.end()
+
+	default:
+		ts.emit(Token{Kind: TokenScalar, Type: t, Value: v,
+			Text: formatKey(ts.cs, v)})
+	}
+}
+
+// ftokens is a helper function to consolidate the logic from the various
+// public tokenize methods which take varying config states.
+func ftokens(cs *ConfigState, v interface{}, emit func(Token)) {
+	ts := tokenState{emit: emit, cs: cs.snapshot()}
+	ts.pointers = make(map[uintptr]bool)
+	ts.tokenize(reflect.ValueOf(v))
+}
+
+// Tokens traverses the passed value the same way Dump does and invokes emit
+// with a stream of tokens which describe it, such as a TokenBeginStruct
+// followed by a TokenFieldName and the tokens for the value of each field and
+// finally a TokenEnd.  This allows custom renderers, such as for HTML tables,
+// to be built without reimplementing the traversal.  See TokenKind for the
+// structure of the stream.
+func Tokens(v interface{}, emit func(Token)) {
+	ftokens(&Config, v, emit)
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// tokenString returns the stream of tokens for the passed value produced with
+// the passed config state in a compact form for comparison.
+func tokenString(cs *spew.ConfigState, v interface{}) string {
+	var parts []string
+	cs.Tokens(v, func(tok spew.Token) {
+		s := tok.Kind.String()
+		switch tok.Kind {
+		case spew.TokenScalar, spew.TokenFieldName:
+			s += "(" + tok.Text + ")"
+		case spew.TokenBeginSlice, spew.TokenBeginMap:
+			s += fmt.Sprintf("(%d)", tok.Len)
+		case spew.TokenNil, spew.TokenPointer:
+			s += "(" + fmt.Sprint(tok.Type) + ")"
+		}
+		parts = append(parts, s)
+	})
+	return strings.Join(parts, " ")
+}
+
+// tokenNode is used to test the tokens for nested and circular structures.
+type tokenNode struct {
+	Name  string
+	Items []int
+	Attrs map[string]bool
+	Next  *tokenNode
+	Any   interface{}
+}
+
+// TestTokens ensures the expected stream of tokens is produced.
+func TestTokens(t *testing.T) {
+	cs := &spew.ConfigState{SortKeys: true}
+	n := &tokenNode{Name: "a", Items: []int{1, 2}, Attrs: map[string]bool{
+		"y": true, "x": false}}
+	n.Next = n
+
+	tests := []struct {
+		cs   *spew.ConfigState
+		in   interface{}
+		want string
+	}{
+		{cs, nil, "Nil(<nil>)"},
+		{cs, 5, "Scalar(5)"},
+		{cs, stringer("s"), "Scalar(stringer s)"},
+		{cs, (*int)(nil), "Nil(*int)"},
+		{cs, []string{}, "BeginSlice(0) End"},
+		{cs, n, "Pointer(*spew_test.tokenNode) BeginStruct " +
+			"FieldName(Name) Scalar(a) " +
+			"FieldName(Items) BeginSlice(2) Scalar(1) Scalar(2) End " +
+			"FieldName(Attrs) BeginMap(2) MapKey Scalar(x) Scalar(false) " +
+			"MapKey Scalar(y) Scalar(true) End " +
+			"FieldName(Next) Cycle " +
+			"FieldName(Any) Nil(interface {}) End"},
+		{&spew.ConfigState{MaxDepth: 1}, [][]int{{1}},
+			"BeginSlice(1) BeginSlice(1) MaxDepth End End"},
+		{&spew.ConfigState{DisableMethods: true}, stringer("s"), "Scalar(s)"},
+	}
+
+	for i, test := range tests {
+		got := tokenString(test.cs, test.in)
+		if got != test.want {
+			t.Errorf("Tokens #%d\n got: %s\nwant: %s", i, got, test.want)
+		}
+	}
+
+	// Ensure the global config is used by the top-level function.
+	var count int
+	spew.Tokens(struct{ A, B int }{}, func(tok spew.Token) {
+		count++
+	})
+	if count != 6 {
+		t.Errorf("Tokens emitted %d tokens, want 6", count)
+	}
+
+	// Ensure unknown kinds are handled.
+	if s := spew.TokenKind(1000).String(); s != "Unknown" {
+		t.Errorf("Unknown TokenKind got: %s", s)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"reflect"
+)
+
+// walkState contains information about the state of a walk operation.
+type walkState struct {
+	fn       func(path string, val reflect.Value) bool
+	depth    int
+	pointers map[uintptr]bool
+	cs       *ConfigState
+}
+
+// walk invokes the callback with the passed value and the path to it and then,
+// unless the callback returns false, walks each of the fields, elements, or map
+// values it contains.  Pointers and interfaces are followed to the values they
+// reference, however circular references are not followed again.
+func (ws *walkState) walk(path string, v reflect.Value) {
+	if !ws.fn(path, v) {
+		return
+	}
+
+	// Indirect through pointers and interfaces while detecting circular
+	// references.
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Ptr {
+			addr := v.Pointer()
+			if ws.pointers[addr] {
+				return
+			}
+			ws.pointers[addr] = true
+			defer delete(ws.pointers, addr)
+		}
+		v = v.Elem()
+	}
+
+	// Detect slices and maps which contain themselves in the same way as
+	// pointers.
+	if addr := refAddr(v); addr != 0 {
+		if ws.pointers[addr] {
+			return
+		}
+		ws.pointers[addr] = true
+		defer delete(ws.pointers, addr)
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct:
+	default:
+		return
+	}
+
+	ws.depth++
+	defer func() { ws.depth-- }()
+	if ws.cs.depthExceeded(ws.depth) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			ws.walk(indexPath(path, i), v.Index(i))
+		}
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		if ws.cs.SortKeys {
+			sortValues(keys, ws.cs)
+		}
+		for _, key := range keys {
+			ws.walk(keyPath(ws.cs, path, key), v.MapIndex(key))
+		}
+
+	case reflect.Struct:
+		vt := v.Type()
+		for _, fi := range visibleFields(ws.cs, vt) {
+			ws.walk(fieldPath(path, fieldName(vt.Field(fi))), v.Field(fi))
+		}
+	}
+}
+
+// fwalk is a helper function to consolidate the logic from the various public
+// walk methods which take varying config states.
+func fwalk(cs *ConfigState, v interface{}, fn func(path string, val reflect.Value) bool) {
+	ws := walkState{fn: fn, cs: cs.snapshot()}
+	ws.pointers = make(map[uintptr]bool)
+	ws.walk("", reflect.ValueOf(v))
+}
+
+// Walk traverses the passed value the same way Dump does, following pointers
+// and detecting circular references, and invokes fn with each value it visits
+// along with the path to it such as .Items[2].Name or .Labels["env"].  The
+// path to the passed value itself is empty.  When fn returns false, the
+// fields, elements, or map values of the value it was invoked with are not
+// visited.  Values are passed to fn as they are found, so pointers and
+// interfaces are passed before being followed and values obtained from
+// unexported struct fields can't be converted to interfaces.
+func Walk(v interface{}, fn func(path string, val reflect.Value) bool) {
+	fwalk(&Config, v, fn)
+}
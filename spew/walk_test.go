@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// walkNode is used to test walking nested and circular structures.
+type walkNode struct {
+	Name     string
+	Children []*walkNode
+	Attrs    map[string]int
+	Parent   *walkNode `spew:"-"`
+	Self     *walkNode
+}
+
+// walkPaths returns the paths and kinds visited by walking the passed value
+// with the passed config state, pruning the subtree at the passed path.
+func walkPaths(cs *spew.ConfigState, v interface{}, prune string) string {
+	var visited []string
+	cs.Walk(v, func(path string, val reflect.Value) bool {
+		visited = append(visited, fmt.Sprintf("%s=%s", path, val.Kind()))
+		return path != prune
+	})
+	return strings.Join(visited, " ")
+}
+
+// TestWalk ensures Walk visits every value with the path to it.
+func TestWalk(t *testing.T) {
+	cs := &spew.ConfigState{SortKeys: true}
+	child := &walkNode{Name: "child"}
+	root := &walkNode{
+		Name:     "root",
+		Children: []*walkNode{child},
+		Attrs:    map[string]int{"b": 2, "a": 1},
+	}
+	child.Parent = root
+	root.Self = root
+
+	tests := []struct {
+		cs    *spew.ConfigState
+		in    interface{}
+		prune string
+		want  string
+	}{
+		{cs, nil, "-", "=invalid"},
+		{cs, 5, "-", "=int"},
+		{cs, []interface{}{1, "a"}, "-", "=slice [0]=interface [1]=interface"},
+		{cs, root, "-", "=ptr .Name=string .Children=slice " +
+			".Children[0]=ptr .Children[0].Name=string " +
+			".Children[0].Children=slice .Children[0].Attrs=map " +
+			".Children[0].Self=ptr " +
+			`.Attrs=map .Attrs["a"]=int .Attrs["b"]=int .Self=ptr`},
+		{cs, root, ".Children", "=ptr .Name=string .Children=slice " +
+			`.Attrs=map .Attrs["a"]=int .Attrs["b"]=int .Self=ptr`},
+		{cs, root, "", "=ptr"},
+		{&spew.ConfigState{MaxDepth: 1}, [][]int{{1}}, "-", "=slice [0]=slice"},
+	}
+
+	for i, test := range tests {
+		got := walkPaths(test.cs, test.in, test.prune)
+		if got != test.want {
+			t.Errorf("Walk #%d\n got: %s\nwant: %s", i, got, test.want)
+		}
+	}
+
+	// Ensure the global config is used by the top-level function.
+	var count int
+	spew.Walk(struct{ A, B int }{}, func(path string, v reflect.Value) bool {
+		count++
+		return true
+	})
+	if count != 3 {
+		t.Errorf("Walk visited %d values, want 3", count)
+	}
+}